@@ -0,0 +1,199 @@
+package goform
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// BindError reports a filled Field whose value could not be converted into
+// the type of the struct field it should be bound to
+type BindError struct {
+	Field string
+	From  reflect.Type
+	To    reflect.Type
+}
+
+func (ø *BindError) Error() string {
+	return fmt.Sprintf("can't bind field %s: %s is not convertible to %s", ø.Field, ø.From, ø.To)
+}
+
+// BindErrors is returned by Bind if some fields could not be bound
+type BindErrors []*BindError
+
+func (ø BindErrors) Error() string {
+	s := []string{}
+	for _, e := range ø {
+		s = append(s, e.Error())
+	}
+	return strings.Join(s, "\n")
+}
+
+// Bind fills the exported fields of the struct dst points to with the values
+// of the filled Fields. The Field name is taken from the form tag of the struct
+// field (e.g. `form:"first_name"`, `form:"-"` skips the field) and defaults to
+// the struct field name. Embedded structs are bound as if their fields were
+// fields of dst.
+// Numbers are converted between the different int and float types, slices
+// element by element and pointers are dereferenced or allocated as needed.
+// The entries of Collection Fields are bound to slices of structs.
+// All values that could not be converted are reported as BindErrors.
+func (ø *FormHandler) Bind(dst interface{}) (err error) {
+	v := reflect.ValueOf(dst)
+	if v.Kind() != reflect.Ptr || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("can't bind to %T: need a non nil pointer to a struct", dst)
+	}
+	errs := BindErrors{}
+	ø.bindStruct(v.Elem(), &errs)
+	if len(errs) > 0 {
+		err = errs
+	}
+	return
+}
+
+func (ø *FormHandler) bindStruct(v reflect.Value, errs *BindErrors) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if sf.Anonymous && sf.Type.Kind() == reflect.Struct {
+			ø.bindStruct(v.Field(i), errs)
+			continue
+		}
+		if sf.PkgPath != "" {
+			continue
+		}
+		name := fieldName(sf)
+		if name == "-" {
+			continue
+		}
+		field := ø.Fields[name]
+		if field == nil || !ø.IsFilledField(field) {
+			continue
+		}
+		if field.Type == Collection {
+			ø.bindEntries(field, v.Field(i), errs)
+			continue
+		}
+		val := ø.get(field)
+		if val == nil {
+			continue
+		}
+		src := reflect.ValueOf(val)
+		converted, ok := convertValue(src, sf.Type)
+		if !ok {
+			*errs = append(*errs, &BindError{Field: name, From: src.Type(), To: sf.Type})
+			continue
+		}
+		v.Field(i).Set(converted)
+	}
+}
+
+// the name of the form Field for the given struct field
+func fieldName(sf reflect.StructField) string {
+	if tag := sf.Tag.Get("form"); tag != "" {
+		if name := strings.Split(tag, ",")[0]; name != "" {
+			return name
+		}
+	}
+	return sf.Name
+}
+
+func convertValue(src reflect.Value, to reflect.Type) (out reflect.Value, ok bool) {
+	if src.Kind() == reflect.Interface {
+		if src.IsNil() {
+			return reflect.Zero(to), true
+		}
+		src = src.Elem()
+	}
+
+	if src.Type().AssignableTo(to) {
+		return src, true
+	}
+
+	switch {
+	case to.Kind() == reflect.Ptr:
+		elem, ok := convertValue(src, to.Elem())
+		if !ok {
+			return out, false
+		}
+		out = reflect.New(to.Elem())
+		out.Elem().Set(elem)
+		return out, true
+	case src.Kind() == reflect.Ptr:
+		if src.IsNil() {
+			return reflect.Zero(to), true
+		}
+		return convertValue(src.Elem(), to)
+	case isNumber(src.Kind()) && isNumber(to.Kind()):
+		return convertNumber(src, to)
+	case src.Kind() == reflect.Slice && to.Kind() == reflect.Slice:
+		out = reflect.MakeSlice(to, src.Len(), src.Len())
+		for i := 0; i < src.Len(); i++ {
+			elem, ok := convertValue(src.Index(i), to.Elem())
+			if !ok {
+				return out, false
+			}
+			out.Index(i).Set(elem)
+		}
+		return out, true
+	}
+	return out, false
+}
+
+func convertNumber(src reflect.Value, to reflect.Type) (out reflect.Value, ok bool) {
+	out = reflect.New(to).Elem()
+	switch to.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		var i int64
+		switch src.Kind() {
+		case reflect.Float32, reflect.Float64:
+			f := src.Float()
+			if f != float64(int64(f)) {
+				return out, false
+			}
+			i = int64(f)
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			i = int64(src.Uint())
+		default:
+			i = src.Int()
+		}
+		if out.OverflowInt(i) {
+			return out, false
+		}
+		out.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		var u uint64
+		switch src.Kind() {
+		case reflect.Float32, reflect.Float64:
+			f := src.Float()
+			if f < 0 || f != float64(uint64(f)) {
+				return out, false
+			}
+			u = uint64(f)
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			if src.Int() < 0 {
+				return out, false
+			}
+			u = uint64(src.Int())
+		default:
+			u = src.Uint()
+		}
+		if out.OverflowUint(u) {
+			return out, false
+		}
+		out.SetUint(u)
+	case reflect.Float32, reflect.Float64:
+		out.Set(src.Convert(to))
+	}
+	return out, true
+}
+
+func isNumber(k reflect.Kind) bool {
+	switch k {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,71 @@
+package goform
+
+import (
+	. "github.com/metakeule/goh4/tag"
+	"testing"
+)
+
+type bindTarget struct {
+	Name    string
+	Age     *int64 `form:"age"`
+	Height  float64
+	Tags    []string
+	Scores  []uint8
+	Address Address
+	Ignored string `form:"-"`
+	Wrong   string `form:"Id"`
+}
+
+func TestBind(t *testing.T) {
+	f := NewForm(
+		Required("Name", String, INPUT()),
+		Optional("age", Int, INPUT()),
+		Optional("Height", Float, INPUT()),
+		Optional("Tags", StringArray, INPUT()),
+		Optional("Scores", IntArray, INPUT()),
+		Optional("Address", Constructor(func() interface{} { return &Address{} }), INPUT()),
+		Optional("Id", Int, INPUT()),
+	)
+
+	_ = f.Parse(map[string]string{
+		"Name":    "Donald",
+		"age":     "144",
+		"Height":  "1.5",
+		"Tags":    "a,b",
+		"Scores":  "1,2,3",
+		"Address": `{"City": "Entenhausen"}`,
+		"Id":      "3",
+	})
+
+	var dst bindTarget
+	e := f.Bind(&dst)
+
+	if dst.Name != "Donald" {
+		err(t, "incorrect Name", dst.Name, "Donald")
+	}
+
+	if dst.Age == nil || *dst.Age != 144 {
+		err(t, "incorrect Age", dst.Age, 144)
+	}
+
+	if dst.Height != 1.5 {
+		err(t, "incorrect Height", dst.Height, 1.5)
+	}
+
+	if len(dst.Tags) != 2 || dst.Tags[1] != "b" {
+		err(t, "incorrect Tags", dst.Tags, []string{"a", "b"})
+	}
+
+	if len(dst.Scores) != 3 || dst.Scores[2] != 3 {
+		err(t, "incorrect Scores", dst.Scores, []uint8{1, 2, 3})
+	}
+
+	if dst.Address.City != "Entenhausen" {
+		err(t, "incorrect Address.City", dst.Address.City, "Entenhausen")
+	}
+
+	errs, ok := e.(BindErrors)
+	if !ok || len(errs) != 1 || errs[0].Field != "Id" {
+		err(t, "int Id should not be bindable to string", e, "can't bind field Id")
+	}
+}
@@ -0,0 +1,349 @@
+package goform
+
+import (
+	"encoding/json"
+	h "github.com/metakeule/goh4"
+	. "github.com/metakeule/goh4/tag"
+	"reflect"
+	"strings"
+)
+
+// the codes of the checks that are repeated in the browser
+var clientCodes = []string{
+	CodeRequired, CodeNotInt, CodeNotFloat, CodeNotInSelection,
+	CodeMin, CodeMax, CodeTooShort, CodeTooLong, CodePattern,
+	CodeTooFewItems, CodeTooManyItems, CodeEmail, CodeURL, CodeUUID,
+}
+
+type clientField struct {
+	Name      string            `json:"name"`
+	Type      string            `json:"type"`
+	Required  bool              `json:"required,omitempty"`
+	Selection []interface{}     `json:"selection,omitempty"`
+	Decimal   string            `json:"decimal,omitempty"` // the NumberFormat of inputs that are not native
+	Grouping  string            `json:"grouping,omitempty"`
+	Rules     []RuleDescription `json:"rules,omitempty"`
+
+	// the classes of the Theme of the form
+	Errors       []h.Class `json:"errors,omitempty"`
+	InvalidInput []h.Class `json:"invalidInput,omitempty"`
+	InvalidLabel []h.Class `json:"invalidLabel,omitempty"`
+}
+
+type clientSpec struct {
+	Fields   []clientField     `json:"fields"`
+	Messages map[string]string `json:"messages"`
+}
+
+// ValidationModule returns a javascript module that repeats the required, type,
+// selection and rule checks of the Fields in the browser. It exports the functions
+// validate(form), which shows the messages with the same markup as RenderSubmission
+// and returns whether the form is valid, and attach(form), which validates the
+// form on submit and blocks the submission until it is valid.
+// The messages are those of the Catalog for the Locale of the form, without a
+// Locale the english Messages are used.
+func (ø *FormHandler) ValidationModule() string {
+	return ø.clientSpec() + validationJS + "export { attach, validate };\n"
+}
+
+// ValidationScript returns a script element that attaches the validation of
+// ValidationModule to the form it is placed in, see AddValidationScript.
+func (ø *FormHandler) ValidationScript() *h.Element {
+	return SCRIPT(h.Html("(function () {\n" + ø.clientSpec() + validationJS +
+		"attach(document.currentScript.closest(\"form\"));\n})();\n"))
+}
+
+// AddValidationScript adds the ValidationScript to the end of the form
+func (ø *FormHandler) AddValidationScript() (el *h.Element) {
+	el = ø.ValidationScript()
+	ø.Element.Add(el)
+	return
+}
+
+func (ø *FormHandler) clientSpec() string {
+	spec := clientSpec{Fields: []clientField{}, Messages: map[string]string{}}
+	for _, field := range ø.orderedFields() {
+		if field.Type == Collection {
+			// the entries are not checked in the browser
+			continue
+		}
+		cf := clientField{
+			Name:         field.Name,
+			Type:         TypeNames[field.Type],
+			Required:     field.Required,
+			Errors:       ø.theme().Errors(field),
+			InvalidInput: ø.theme().InvalidInput(field, field.Element.Fields()[0]),
+			InvalidLabel: ø.theme().InvalidLabel(field),
+		}
+		if field.Selection != nil {
+			sel := reflect.ValueOf(field.Selection)
+			for i := 0; i < sel.Len(); i++ {
+				cf.Selection = append(cf.Selection, sel.Index(i).Interface())
+			}
+		}
+		switch field.Type {
+		case Int, Float, IntArray, FloatArray:
+			if nf, ok := field.numberFormat(field.locale(ø)); ok {
+				cf.Decimal, cf.Grouping = nf.Decimal, nf.Grouping
+			}
+		}
+		for _, r := range field.rules() {
+			if hasString(clientCodes, r.Code) {
+				cf.Rules = append(cf.Rules, r)
+			}
+		}
+		spec.Fields = append(spec.Fields, cf)
+	}
+	for _, code := range clientCodes {
+		text, ok := "", false
+		if ø.messageLocale() != "" {
+			text, ok = ø.catalog().Translate(ø.messageLocale(), code)
+		}
+		if !ok {
+			text = Messages["en"][code]
+		}
+		spec.Messages[code] = text
+	}
+	// json.Marshal escapes <, > and &, so the spec can't end the script element
+	b, _ := json.Marshal(spec)
+	return "const spec = " + strings.TrimSpace(string(b)) + ";\n"
+}
+
+const validationJS = `
+function values(form, f) {
+  const vals = [];
+  form.querySelectorAll('[name="' + f.name + '"]').forEach(function (el) {
+    if ((el.type === "checkbox" || el.type === "radio") && !el.checked) return;
+    if (el.tagName === "SELECT") {
+      for (const o of el.selectedOptions) vals.push(o.value);
+      return;
+    }
+    if (el.type === "file") {
+      for (const file of el.files) vals.push(file.name);
+      return;
+    }
+    if (el.type === "email" && el.multiple) {
+      for (const v of el.value.split(",")) vals.push(v.trim());
+      return;
+    }
+    vals.push(el.value);
+  });
+  return vals.filter(function (v) { return v !== ""; });
+}
+
+function canonical(f, s, allowDecimal) {
+  let sign = "";
+  if (s[0] === "-" || s[0] === "+") { sign = s[0]; s = s.slice(1); }
+  let intPart = s, frac = "";
+  const i = s.indexOf(f.decimal);
+  if (i >= 0) {
+    if (!allowDecimal) return null;
+    intPart = s.slice(0, i); frac = s.slice(i + f.decimal.length);
+    if (!/^[0-9]+$/.test(frac)) return null;
+  }
+  const groups = f.grouping ? intPart.split(f.grouping) : [intPart];
+  for (let j = 0; j < groups.length; j++) {
+    const g = groups[j];
+    if (!/^[0-9]+$/.test(g) || (j === 0 && groups.length > 1 && g.length > 3) || (j > 0 && g.length !== 3)) return null;
+  }
+  return sign + groups.join("") + (frac ? "." + frac : "");
+}
+
+// reports if the underscores of a number are placed like strconv accepts them:
+// only between digits or after a base prefix
+function underscoreOK(s) {
+  let saw = "^", i = 0, hex = false;
+  if (s[0] === "+" || s[0] === "-") s = s.slice(1);
+  if (s.length >= 2 && s[0] === "0" && "bBoOxX".indexOf(s[1]) >= 0) {
+    i = 2; saw = "0"; hex = s[1] === "x" || s[1] === "X";
+  }
+  for (; i < s.length; i++) {
+    const c = s[i];
+    if ((c >= "0" && c <= "9") || (hex && /[a-fA-F]/.test(c))) { saw = "0"; continue; }
+    if (c === "_") {
+      if (saw !== "0") return false;
+      saw = "_";
+      continue;
+    }
+    if (saw === "_") return false;
+    saw = "!";
+  }
+  return saw !== "_";
+}
+
+// parses an int like strconv.ParseInt(s, 0, 32), null if it is invalid
+function goInt(s) {
+  if (!underscoreOK(s)) return null;
+  let t = s.split("_").join(""), negative = false;
+  if (t[0] === "+" || t[0] === "-") { negative = t[0] === "-"; t = t.slice(1); }
+  let re = /^[0-9]+$/, prefix = "", digits = t;
+  if (/^0[xX]/.test(t)) { re = /^[0-9a-fA-F]+$/; prefix = "0x"; digits = t.slice(2); }
+  else if (/^0[oO]/.test(t)) { re = /^[0-7]+$/; prefix = "0o"; digits = t.slice(2); }
+  else if (/^0[bB]/.test(t)) { re = /^[01]+$/; prefix = "0b"; digits = t.slice(2); }
+  else if (t.length > 1 && t[0] === "0") { re = /^[0-7]+$/; prefix = "0o"; digits = t.slice(1); }
+  if (!re.test(digits)) return null;
+  const n = negative ? -BigInt(prefix + digits) : BigInt(prefix + digits);
+  return n < -2147483648n || n > 2147483647n ? null : Number(n);
+}
+
+// parses a float like strconv.ParseFloat(s, 32), null if it is invalid
+function goFloat(s) {
+  if (/^[+-]?(inf|infinity)$/i.test(s)) return s[0] === "-" ? -Infinity : Infinity;
+  if (/^nan$/i.test(s)) return NaN;
+  if (!underscoreOK(s)) return null;
+  const t = s.split("_").join("");
+  let n;
+  const hex = /^([+-]?)0[xX]([0-9a-fA-F]*)\.?([0-9a-fA-F]*)[pP]([+-]?[0-9]+)$/.exec(t);
+  if (hex) {
+    if (hex[2] + hex[3] === "") return null;
+    n = parseInt(hex[2] + hex[3], 16) * Math.pow(2, Number(hex[4]) - 4 * hex[3].length);
+    if (hex[1] === "-") n = -n;
+  } else if (/^[+-]?([0-9]+\.?[0-9]*|\.[0-9]+)([eE][+-]?[0-9]+)?$/.test(t)) {
+    n = Number(t);
+  } else {
+    return null;
+  }
+  n = Math.fround(n);
+  return isFinite(n) ? n : null;
+}
+
+// parses a number like the server: in the NumberFormat of the Field, if it is
+// valid in it, otherwise in the format of strconv. Floats have float32 precision.
+function number(f, s, allowDecimal) {
+  if (f.decimal) {
+    const c = canonical(f, s.trim(), allowDecimal);
+    if (c !== null) s = c;
+  }
+  return allowDecimal ? goFloat(s) : goInt(s);
+}
+
+function format(v) {
+  return Array.isArray(v) ? "[" + v.join(" ") + "]" : String(v);
+}
+
+function message(f, code, value, params) {
+  let text = spec.messages[code].split("{field}").join(f.name).split("{value}").join(format(value));
+  for (const k in params || {}) text = text.split("{" + k + "}").join(format(params[k]));
+  return text;
+}
+
+function isURL(s) {
+  try {
+    const u = new URL(s);
+    return u.protocol !== "" && u.host !== "";
+  } catch (e) {
+    return false;
+  }
+}
+
+function check(form, f) {
+  const vals = values(form, f), errs = [];
+  if (vals.length === 0) {
+    if (f.required) errs.push(message(f, "required", "", null));
+    return errs;
+  }
+  const isArray = f.type.endsWith("_array");
+  const value = isArray ? vals : vals[0];
+  // floats are compared in float32 precision, like on the server
+  const isFloat = f.type === "float" || f.type === "float_array";
+  const bound = isFloat ? Math.fround : Number;
+  for (const v of isArray ? vals : [vals[0]]) {
+    let typed = v;
+    if (f.type === "int" || f.type === "int_array" || isFloat) {
+      typed = number(f, v, isFloat);
+      if (typed === null) {
+        errs.push(message(f, isFloat ? "not_float" : "not_int", v, null));
+        continue;
+      }
+    }
+    if (f.selection && !isArray && !f.selection.some(function (s) { return (isFloat ? bound(s) : s) === typed; })) {
+      errs.push(message(f, "not_in_selection", v, { selection: f.selection }));
+    }
+    for (const r of f.rules || []) {
+      const p = r.params || {};
+      let ok = true;
+      switch (r.code) {
+        case "min": ok = typeof typed !== "number" || !(typed < bound(p.min)); break;
+        case "max": ok = typeof typed !== "number" || !(typed > bound(p.max)); break;
+        case "too_short": ok = typeof typed !== "string" || [...typed].length >= p.min_length; break;
+        case "too_long": ok = typeof typed !== "string" || [...typed].length <= p.max_length; break;
+        case "pattern":
+          try { ok = new RegExp("^(?:" + p.pattern + ")$", "u").test(typed); } catch (e) { ok = true; }
+          break;
+        case "email": ok = /^[^\s@<>]+@[^\s@<>]+$/.test(typed); break;
+        case "url": ok = isURL(typed); break;
+        case "uuid": ok = /^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$/.test(typed); break;
+      }
+      if (!ok) errs.push(message(f, r.code, value, p));
+    }
+  }
+  for (const r of f.rules || []) {
+    const p = r.params || {};
+    if ((r.code === "too_few_items" && vals.length < p.min_items) || (r.code === "too_many_items" && vals.length > p.max_items)) {
+      errs.push(message(f, r.code, value, p));
+    }
+  }
+  return errs;
+}
+
+function show(form, f, errs) {
+  const id = f.name + "-errors";
+  const old = form.querySelector('[id="' + id + '"]');
+  if (old) old.remove();
+  const label = form.querySelector('label[for="' + f.name + '"]');
+  if (label) for (const c of f.invalidLabel || []) label.classList.toggle(c, errs.length > 0);
+  const help = form.querySelector('[id="' + f.name + '-help"]');
+  const inputs = form.querySelectorAll('[name="' + f.name + '"]');
+  inputs.forEach(function (el) {
+    for (const c of f.invalidInput || []) el.classList.toggle(c, errs.length > 0);
+    if (errs.length > 0) {
+      el.setAttribute("aria-invalid", "true");
+      el.setAttribute("aria-describedby", help ? help.id + " " + id : id);
+    } else {
+      el.removeAttribute("aria-invalid");
+      if (help) el.setAttribute("aria-describedby", help.id);
+      else el.removeAttribute("aria-describedby");
+    }
+  });
+  if (errs.length === 0 || inputs.length === 0) return;
+  const ul = document.createElement("ul");
+  ul.className = (f.errors || []).join(" ");
+  ul.id = id;
+  for (const text of errs) {
+    const li = document.createElement("li");
+    li.textContent = text;
+    ul.appendChild(li);
+  }
+  const last = inputs[inputs.length - 1];
+  (last.closest("label") || last).after(ul);
+}
+
+function validate(form) {
+  let valid = true;
+  for (const f of spec.fields) {
+    const errs = check(form, f);
+    show(form, f, errs);
+    if (errs.length > 0) valid = false;
+  }
+  return valid;
+}
+
+function attach(form) {
+  form.noValidate = true;
+  form.addEventListener("submit", function (e) {
+    if (!validate(form)) {
+      e.preventDefault();
+      const first = form.querySelector('[aria-invalid="true"]');
+      if (first) first.focus();
+    }
+  });
+  // fields that show errors are checked again when they change
+  form.addEventListener("change", function (e) {
+    for (const f of spec.fields) {
+      if (f.name === e.target.name && form.querySelector('[id="' + f.name + '-errors"]')) {
+        show(form, f, check(form, f));
+      }
+    }
+  });
+}
+`
@@ -0,0 +1,188 @@
+package goform
+
+import (
+	"encoding/json"
+	"errors"
+	. "github.com/metakeule/goh4/tag"
+	"os/exec"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestValidationModule(t *testing.T) {
+	f := NewForm(
+		Validators(Required("Name", String, INPUT()), MinLength(2), Pattern("[a-z<>]+")),
+		Selection(Optional("Size", Int, INPUT()), 1, 2),
+		Optional("Price", Float, INPUT()),
+	)
+	f.Locale = "de"
+
+	js := f.ValidationModule()
+
+	for _, s := range []string{
+		`{"name":"Name","type":"string","required":true,"rules":[{"code":"too_short","params":{"min_length":2}},{"code":"pattern","params":{"pattern":"[a-z\u003c\u003e]+"}}],"errors":["field-errors"],"invalidLabel":["error"]}`,
+		`{"name":"Size","type":"int","selection":[1,2],"decimal":",","grouping":".","errors":["field-errors"],"invalidLabel":["error"]}`,
+		`"required":"Pflichtfeld"`,
+		`export { attach, validate };`,
+	} {
+		if !strings.Contains(js, s) {
+			err(t, "missing in validation module", js, s)
+		}
+	}
+
+	f.Locale = ""
+	if js := f.ValidationModule(); !strings.Contains(js, `"required":"required"`) {
+		err(t, "messages without locale should be english", js, `"required":"required"`)
+	}
+
+	script := f.AddValidationScript()
+	if script.Tag() != "script" || !strings.Contains(script.String(), `attach(document.currentScript.closest("form"))`) {
+		err(t, "incorrect validation script", script.String(), "script that attaches to the form")
+	}
+}
+
+// runs check and attach of the validation script for each case on a fake DOM
+// and prints the error codes, whether the submission was blocked and the
+// name of the focused input
+const clientTestHarness = `
+for (const k in spec.messages) spec.messages[k] = k;
+let focused = null;
+var document = { createElement: function () { return { appendChild: function () {} }; } };
+function input(name, value) {
+  const attrs = {};
+  return {
+    name: name, value: value, type: "text", tagName: "INPUT",
+    classList: { toggle: function () {} },
+    setAttribute: function (k, v) { attrs[k] = v; },
+    removeAttribute: function (k) { delete attrs[k]; },
+    getAttribute: function (k) { return k in attrs ? attrs[k] : null; },
+    closest: function () { return null; },
+    after: function () {},
+    focus: function () { focused = this; },
+  };
+}
+function fakeForm(vals) {
+  const inputs = [], listeners = {};
+  for (const name in vals) for (const v of vals[name]) inputs.push(input(name, v));
+  return {
+    querySelectorAll: function (sel) {
+      const m = /^\[name="(.*)"\]$/.exec(sel);
+      return m ? inputs.filter(function (el) { return el.name === m[1]; }) : [];
+    },
+    querySelector: function (sel) {
+      if (sel !== '[aria-invalid="true"]') return null;
+      return inputs.find(function (el) { return el.getAttribute("aria-invalid") === "true"; }) || null;
+    },
+    addEventListener: function (type, fn) { listeners[type] = fn; },
+    submit: function () {
+      let prevented = false;
+      listeners.submit({ preventDefault: function () { prevented = true; } });
+      return prevented;
+    },
+  };
+}
+const results = {};
+for (const name in cases) {
+  const form = fakeForm(cases[name]), errs = {};
+  for (const f of spec.fields) {
+    const e = check(form, f);
+    if (e.length > 0) errs[f.name] = e.sort();
+  }
+  focused = null;
+  attach(form);
+  results[name] = { errors: errs, blocked: form.submit(), focused: focused ? focused.name : "" };
+}
+console.log(JSON.stringify(results));
+`
+
+func newClientTestForm() *FormHandler {
+	f := NewForm(
+		Validators(Required("Name", String, INPUT()), MinLength(2)),
+		Validators(Optional("Count", Int, INPUT()), Min(1)),
+		Validators(Optional("Price", Float, INPUT()), Max(1.8)),
+		Selection(Optional("Size", Int, INPUT()), 1, 16),
+		Selection(Optional("Height", Float, INPUT()), 1.82),
+		Validators(Optional("Tags", IntArray, INPUT()), MaxItems(2)),
+		Validators(Optional("Mail", String, INPUT()), Email()),
+	)
+	f.Locale = "de"
+	return f
+}
+
+// the browser has to come to the same result as the server
+func TestValidationModuleBehavior(t *testing.T) {
+	node, e := exec.LookPath("node")
+	if e != nil {
+		t.Skip("node is not installed")
+	}
+
+	cases := map[string]map[string][]string{
+		"valid": {
+			"Name": {"Donald"}, "Count": {"0x10"}, "Price": {"1.80000001"}, "Size": {"0x10"},
+			"Height": {"1,82"}, "Tags": {"1", "0b10"}, "Mail": {"donald@duck.de"},
+		},
+		"rules": {
+			"Name": {"D"}, "Count": {"0"}, "Price": {"1.9"}, "Size": {"3"},
+			"Height": {"1,83"}, "Tags": {"1", "2", "3"}, "Mail": {"donald"},
+		},
+		"syntax": {
+			"Name": {""}, "Count": {"1,5"}, "Price": {"1e39"}, "Size": {"08"}, "Tags": {"x", "2147483648"},
+		},
+		"strconv": {
+			"Name": {"Daisy"}, "Count": {"1_000"}, "Price": {"0x1p-2"}, "Size": {"017"}, "Height": {"1.82"},
+		},
+	}
+
+	f := newClientTestForm()
+	js, _ := json.Marshal(cases)
+	cmd := exec.Command(node)
+	cmd.Stdin = strings.NewReader(f.clientSpec() + validationJS + "const cases = " + string(js) + ";\n" + clientTestHarness)
+	out, e := cmd.Output()
+	if e != nil {
+		t.Fatalf("can't run the validation script: %s", e)
+	}
+
+	var results map[string]struct {
+		Errors  map[string][]string
+		Blocked bool
+		Focused string
+	}
+	if e := json.Unmarshal(out, &results); e != nil {
+		t.Fatalf("invalid output %s: %s", out, e)
+	}
+
+	for name, vals := range cases {
+		f = newClientTestForm()
+		parseErr := f.ParseFormValues(vals)
+		server := map[string][]string{}
+		for field, errs := range f.FieldErrors {
+			codes, parseCodes := []string{}, []string{}
+			for _, fe := range errs {
+				ve := &ValidationError{}
+				errors.As(fe, &ve)
+				codes = append(codes, ve.Code)
+				if ve.Code == CodeNotInt || ve.Code == CodeNotFloat {
+					parseCodes = append(parseCodes, ve.Code)
+				}
+			}
+			// the server checks the rules of unparsable values with the zero value,
+			// the browser only reports that they can't be parsed
+			if len(parseCodes) > 0 {
+				codes = parseCodes
+			}
+			sort.Strings(codes)
+			server[field.Name] = codes
+		}
+
+		client := results[name]
+		if !reflect.DeepEqual(client.Errors, server) {
+			err(t, "the browser should report the errors of the server for "+name, client.Errors, server)
+		}
+
+		if client.Blocked != (parseErr != nil) || (client.Blocked && client.Focused == "") {
+			err(t, "the submission of "+name+" should be blocked if it is invalid", client.Blocked, parseErr != nil)
+		}
+	}
+}
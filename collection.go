@@ -0,0 +1,301 @@
+package goform
+
+import (
+	h "github.com/metakeule/goh4"
+	. "github.com/metakeule/goh4/tag"
+	"reflect"
+	"strconv"
+)
+
+// the limit of entries of Collection Fields without MaxItems Validator
+const DefaultMaxEntries = 100
+
+// Type makes a FormDefinition usable as Type of a Field: the Field is a Collection
+// whose entries are forms of the definition, e.g.
+//
+//	Optional("Addresses", addressDefinition, LEGEND(h.Text("Addresses")))
+//
+// The Fields of the entries are named by their path, e.g. "Addresses[0].City", so
+// that they are parsed from indexed names and their errors are keyed by path.
+// The number of entries is limited by the MinItems and MaxItems Validators,
+// without MaxItems to DefaultMaxEntries.
+// Nested Collections are supported, also in entries added in the browser.
+func (ø *FormDefinition) Type() Type { return Collection }
+
+// the limits of entries of the Collection, validated reports if the maximum is
+// checked by a MaxItems Validator
+func (ø *Field) entryLimits() (min int, max int, validated bool) {
+	max = DefaultMaxEntries
+	for _, v := range ø.Validators {
+		switch r := v.(type) {
+		case MinItemsRule:
+			min = r.Count
+		case MaxItemsRule:
+			max, validated = r.Count, true
+		}
+	}
+	return
+}
+
+// creates an entry of the Collection with the Fields renamed to their path
+func (ø *Field) newEntry(index string) *FormHandler {
+	entry := ø.Definition.NewSubmission().FormHandler
+	prefix := ø.Name + "[" + index + "]."
+	for _, f := range entry.orderedFields() {
+		f.rename(prefix + f.Name)
+	}
+	entry.Element = DIV(h.Class("entry"))
+	for _, s := range entry.Order {
+		switch v := s.(type) {
+		case *Field:
+			entry.Element.Add(v.Element)
+		case *Group:
+			entry.Element.Add(v.Element)
+		default:
+			entry.Element.Add(s)
+		}
+	}
+	entry.Element.Add(BUTTON(h.Class("remove"), h.Attr("type", "button"), h.Text(textOr(ø.RemoveText, "Remove"))))
+	return entry
+}
+
+// renames the Field and its inputs, labels and help text
+func (ø *Field) rename(name string) {
+	help := ø.Element.Any(h.Id(ø.helpId()))
+	ø.Name = name
+	if ø.Type == Collection {
+		ø.entries = nil
+		ø.setCollectionInfos()
+		return
+	}
+	fs := ø.Element.Fields()
+	fs[0].Add(h.Id(name))
+	for _, el := range fs {
+		el.Add(h.Attr("name", name))
+		if help != nil {
+			el.Add(h.Attr("aria-describedby", ø.helpId()))
+		}
+	}
+	if help != nil {
+		help.Add(h.Id(ø.helpId()))
+	}
+	if label := ø.Element.Any(h.Tag("label")); label != nil {
+		label.Add(h.Attr("for", name))
+	}
+}
+
+// renders the minimal number of empty entries
+func (ø *Field) setCollectionInfos() {
+	min, _, _ := ø.entryLimits()
+	for len(ø.entries) < min {
+		ø.entries = append(ø.entries, ø.newEntry(strconv.Itoa(len(ø.entries))))
+	}
+	ø.renderCollection()
+}
+
+// (re)renders the entries, the template for new entries and the add button
+func (ø *Field) renderCollection() {
+	min, max, _ := ø.entryLimits()
+	container := ø.Element.Any(h.And_(h.Tag("div"), h.Class("collection")))
+	if container == nil {
+		container = DIV(h.Class("collection"))
+		ø.Element.Add(container)
+	}
+	container.Clear()
+	container.Add(h.Id(ø.Name), h.Attr("data-min", strconv.Itoa(min), "data-max", strconv.Itoa(max)))
+
+	for _, entry := range ø.entries {
+		container.Add(entry.Element)
+	}
+	ø.template = ø.newEntry("__index__")
+	template := h.NewElement(h.Tag("template"))
+	template.Add(ø.template.Element)
+	container.Add(
+		template,
+		BUTTON(h.Class("add"), h.Attr("type", "button"), h.Text(textOr(ø.AddText, "Add"))),
+		SCRIPT(h.Html(collectionJS)),
+	)
+	if ø.theme != nil {
+		ø.styleEntries(ø.theme, func(el *h.Element, c h.Class) { el.AddClass(c) })
+	}
+}
+
+// applies fn to the classes of the Theme for the Fields and buttons of the entries
+func (ø *Field) styleEntries(t Theme, fn func(*h.Element, h.Class)) {
+	entries := ø.entries
+	if ø.template != nil {
+		entries = append(entries[:len(entries):len(entries)], ø.template)
+	}
+	for _, entry := range entries {
+		for _, f := range entry.orderedFields() {
+			f.styleElements(t, fn)
+		}
+	}
+	for _, button := range ø.Element.All(h.Tag("button")) {
+		if button.HasClass("add") || button.HasClass("remove") {
+			for _, c := range t.Button() {
+				fn(button, c)
+			}
+		}
+	}
+}
+
+// the inputs of the Field, for Collections there are none (the inputs belong to the entries)
+func (ø *Field) inputs() []*h.Element {
+	if ø.Type == Collection {
+		return nil
+	}
+	return ø.Element.Fields()
+}
+
+// parses the entries of the Collection k from the source, up to one more than allowed
+func (ø *FormHandler) parseCollection(k *Field, src ValueSource) {
+	_, max, validated := k.entryLimits()
+	entries := []*FormHandler{}
+	for i := 0; i <= max; i++ {
+		index := strconv.Itoa(i)
+		if !k.Definition.hasValues(src, k.Name+"["+index+"].") {
+			break
+		}
+		entry := k.newEntry(index)
+		entry.Locale, entry.requestLocale = ø.Locale, ø.requestLocale
+		entry.Catalog = ø.Catalog
+		entry.beforeParsing()
+		entry.parseSource(src)
+		entries = append(entries, entry)
+	}
+	if len(entries) == 0 {
+		return
+	}
+	if len(entries) > max && !validated {
+		// further entries are not parsed, so the Collection must not be accepted
+		ø.AddFieldError(k, newValidationError(k, CodeTooManyItems, len(entries), Params{"max_items": max}, "more than %d entries", max))
+	}
+	k.entries = entries
+	k.renderCollection()
+	ø.Collections[k] = entries
+	ø.FilledFields = append(ø.FilledFields, k.Name)
+}
+
+// validates the entries of the Collection and adds their errors to the Collection
+func (ø *FormHandler) checkEntries(k *Field) {
+	for i, entry := range ø.Collections[k] {
+		entry.runValidation()
+		for _, errs := range entry.FieldErrors {
+			ø.FieldErrors[k] = append(ø.FieldErrors[k], errs...)
+		}
+		path := k.Name + "[" + strconv.Itoa(i) + "]"
+		for _, err := range entry.GeneralValidationErrors {
+			ø.AddFieldError(k, entryError(path, err))
+		}
+	}
+}
+
+// keys a general validation error of an entry by the path of the entry
+func entryError(path string, err error) *ValidationError {
+	if ve, ok := err.(*ValidationError); ok {
+		c := *ve
+		c.Field = path
+		return &c
+	}
+	return &ValidationError{Code: CodeInvalid, Field: path, Message: err.Error()}
+}
+
+// the key of an error of the Field: the path for errors of Collection entries, otherwise the Field name
+func errorKey(field *Field, err error) string {
+	if ve, ok := err.(*ValidationError); ok && ve.Field != "" {
+		return ve.Field
+	}
+	return field.Name
+}
+
+// the errors of the Field itself, without those of Collection entries
+func (ø *Field) ownErrors(errs []error) (own []error) {
+	for _, err := range errs {
+		if errorKey(ø, err) == ø.Name {
+			own = append(own, err)
+		}
+	}
+	return
+}
+
+// renders one entry for each element of v, a slice of maps (see SetValues) or structs (see SetValuesFrom)
+func (ø *Field) setEntryValues(v interface{}, locale string) {
+	ø.entries = nil
+	rv := reflect.ValueOf(v)
+	if rv.Kind() == reflect.Slice || rv.Kind() == reflect.Array {
+		for i := 0; i < rv.Len(); i++ {
+			entry := ø.newEntry(strconv.Itoa(i))
+			entry.Locale = locale
+			if m, ok := rv.Index(i).Interface().(map[string]interface{}); ok {
+				entry.SetValues(m)
+			} else {
+				entry.SetValuesFrom(rv.Index(i).Interface())
+			}
+			ø.entries = append(ø.entries, entry)
+		}
+	}
+	ø.setCollectionInfos()
+}
+
+// binds the entries of the Collection to a slice of structs or struct pointers
+func (ø *FormHandler) bindEntries(field *Field, dst reflect.Value, errs *BindErrors) {
+	t := dst.Type()
+	if t.Kind() != reflect.Slice || !(t.Elem().Kind() == reflect.Struct || t.Elem().Kind() == reflect.Ptr && t.Elem().Elem().Kind() == reflect.Struct) {
+		*errs = append(*errs, &BindError{Field: field.Name, From: reflect.TypeOf([]map[string]interface{}{}), To: t})
+		return
+	}
+	entries := ø.Collections[field]
+	out := reflect.MakeSlice(t, len(entries), len(entries))
+	for i, entry := range entries {
+		elem := out.Index(i)
+		if elem.Kind() == reflect.Ptr {
+			elem.Set(reflect.New(t.Elem().Elem()))
+			elem = elem.Elem()
+		}
+		entry.bindStruct(elem, errs)
+	}
+	dst.Set(out)
+}
+
+// adds and removes entries in the browser and renumbers the names of the entries,
+// so that they are parsed without gaps. The names inside the templates of nested
+// Collections are renumbered too, and since the id of a nested Collection changes
+// when its outer entry is renumbered, the prefix is read on each renumbering.
+const collectionJS = `(function () {
+  const c = document.currentScript.parentNode;
+  const min = Number(c.dataset.min), max = Number(c.dataset.max);
+  const template = c.querySelector(":scope > template"), add = c.querySelector(":scope > button.add");
+  const entries = function () { return c.querySelectorAll(":scope > .entry"); };
+  function rename(root, prefix, i) {
+    root.querySelectorAll("[name], [id], [for], [aria-describedby]").forEach(function (el) {
+      for (const a of ["name", "id", "for", "aria-describedby"]) {
+        const v = el.getAttribute(a);
+        if (v === null) continue;
+        el.setAttribute(a, v.split(prefix).map(function (part, j) {
+          return j === 0 ? part : part.replace(/^([0-9]+|__index__)\]/, i + "]");
+        }).join(prefix));
+      }
+    });
+    root.querySelectorAll("template").forEach(function (t) { rename(t.content, prefix, i); });
+  }
+  function renumber() {
+    const prefix = c.id + "[";
+    entries().forEach(function (entry, i) { rename(entry, prefix, i); });
+    const n = entries().length;
+    add.disabled = n >= max;
+    c.querySelectorAll(":scope > .entry > button.remove").forEach(function (b) { b.disabled = n <= min; });
+  }
+  add.addEventListener("click", function () {
+    c.insertBefore(template.content.cloneNode(true), template);
+    renumber();
+  });
+  c.addEventListener("click", function (e) {
+    if (e.target.classList.contains("remove") && e.target.parentNode.parentNode === c) {
+      e.target.parentNode.remove();
+      renumber();
+    }
+  });
+  renumber();
+})();
+`
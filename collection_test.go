@@ -0,0 +1,188 @@
+package goform
+
+import (
+	"encoding/json"
+	"errors"
+	h "github.com/metakeule/goh4"
+	. "github.com/metakeule/goh4/tag"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+var addressDefinition = NewFormDefinition(func() *FormHandler {
+	return NewForm(
+		Required("City", String, LABEL(h.Text("City"), INPUT())),
+		Validators(Optional("Zip", Int, INPUT()), Min(1000)),
+	)
+})
+
+func newCollectionForm() *FormHandler {
+	return NewForm(
+		Required("Name", String, INPUT()),
+		Validators(Optional("Addresses", addressDefinition, LEGEND(h.Text("Addresses"))), MinItems(1), MaxItems(2)),
+	)
+}
+
+func TestCollectionRender(t *testing.T) {
+	f := newCollectionForm()
+	c := f.Any(h.Id("Addresses"))
+
+	if c == nil || c.Attribute("data-min") != "1" || c.Attribute("data-max") != "2" {
+		err(t, "incorrect collection container", f.String(), `data-min="1" data-max="2"`)
+		return
+	}
+
+	if in := c.Any(h.Attr("name", "Addresses[0].City")); in == nil || in.Attribute("id") != "Addresses[0].City" {
+		err(t, "missing first entry", c.String(), "Addresses[0].City")
+	}
+
+	if c.Any(h.Attr("for", "Addresses[0].City")) == nil {
+		err(t, "label of entry should be renamed", c.String(), `for="Addresses[0].City"`)
+	}
+
+	if c.Any(h.Attr("name", "Addresses[__index__].City")) == nil || c.Any(h.Class("add")) == nil || c.Any(h.Class("remove")) == nil {
+		err(t, "missing template or controls", c.String(), "template, add and remove buttons")
+	}
+}
+
+func TestCollectionParse(t *testing.T) {
+	f := newCollectionForm()
+	e := f.ParseFormValues(map[string][]string{
+		"Name":              {"Donald"},
+		"Addresses[0].City": {"Duckburg"},
+		"Addresses[0].Zip":  {"12345"},
+		"Addresses[1].City": {""},
+		"Addresses[1].Zip":  {"99"},
+	})
+
+	fe := &FormErrors{}
+	if !errors.As(e, &fe) {
+		t.Fatalf("expected FormErrors, got %v", e)
+	}
+
+	if len(fe.FieldErrors["Addresses[1].City"]) != 1 || len(fe.FieldErrors["Addresses[1].Zip"]) != 1 || len(fe.FieldErrors) != 2 {
+		err(t, "errors should be keyed by path", fe.FieldErrors, "Addresses[1].City, Addresses[1].Zip")
+	}
+
+	entries := f.Get("Addresses").([]map[string]interface{})
+	if len(entries) != 2 || entries[0]["City"] != "Duckburg" || entries[0]["Zip"] != 12345 {
+		err(t, "incorrect entries", entries, "Duckburg 12345")
+	}
+
+	doc, _ := f.ErrorsJSON()
+	if !strings.Contains(string(doc), `"Addresses[1].Zip":[{"code":"min"`) {
+		err(t, "error document should be keyed by path", string(doc), "Addresses[1].Zip")
+	}
+
+	f.RenderSubmission()
+	if f.Any(h.Id("Addresses[1].City-errors")) == nil || f.Any(h.Id("Addresses-errors")) != nil {
+		err(t, "errors should be rendered at the entries", f.String(), "Addresses[1].City-errors")
+	}
+}
+
+func TestCollectionLimits(t *testing.T) {
+	f := newCollectionForm()
+	e := f.ParseFormValues(map[string][]string{
+		"Name":              {"Donald"},
+		"Addresses[0].City": {"a"},
+		"Addresses[1].City": {"b"},
+		"Addresses[2].City": {"c"},
+		"Addresses[3].City": {"d"},
+	})
+
+	fe := &FormErrors{}
+	if !errors.As(e, &fe) || len(fe.FieldErrors["Addresses"]) != 1 {
+		t.Fatalf("expected an error for too many entries, got %v", e)
+	}
+
+	if ve := fe.FieldErrors["Addresses"][0].(*ValidationError); ve.Code != CodeTooManyItems {
+		err(t, "incorrect code", ve.Code, CodeTooManyItems)
+	}
+
+	// one more entry than allowed is parsed
+	if n := len(f.Collections[f.Field("Addresses")]); n != 3 {
+		err(t, "incorrect number of parsed entries", n, 3)
+	}
+
+	// without MaxItems Validator the entries are limited to DefaultMaxEntries
+	f = NewForm(Optional("Addresses", addressDefinition, LEGEND()))
+	vals := map[string][]string{}
+	for i := 0; i <= DefaultMaxEntries; i++ {
+		vals["Addresses["+strconv.Itoa(i)+"].City"] = []string{"Duckburg"}
+	}
+	e = f.ParseFormValues(vals)
+	if !errors.As(e, &fe) || len(fe.FieldErrors["Addresses"]) != 1 || fe.FieldErrors["Addresses"][0].(*ValidationError).Code != CodeTooManyItems {
+		t.Fatalf("expected an error for more than %d entries, got %v", DefaultMaxEntries, e)
+	}
+}
+
+func TestCollectionJSONAndBind(t *testing.T) {
+	f := newCollectionForm()
+	var addresses []map[string]interface{}
+	f.Action = func(f *FormHandler) error {
+		addresses = f.Get("Addresses").([]map[string]interface{})
+		return nil
+	}
+
+	e := f.ParseJSON(strings.NewReader(`{"Name": "Donald", "Addresses": [{"City": "Duckburg", "Zip": 12345}, {"City": "Bonn"}]}`))
+	if e != nil {
+		t.Fatalf("unexpected error: %s", e)
+	}
+
+	if len(addresses) != 2 || addresses[1]["City"] != "Bonn" {
+		err(t, "incorrect entries from json", addresses, "Duckburg, Bonn")
+	}
+
+	var person struct {
+		Name      string
+		Addresses []*Address
+	}
+	if e := f.Bind(&person); e != nil {
+		t.Fatalf("unexpected error: %s", e)
+	}
+
+	if len(person.Addresses) != 2 || person.Addresses[0].Zip != 12345 || person.Addresses[1].City != "Bonn" {
+		err(t, "incorrect bound entries", person.Addresses, "Duckburg 12345, Bonn")
+	}
+}
+
+func TestCollectionBuildsSubmittedEntries(t *testing.T) {
+	builds := 0
+	def := NewFormDefinition(func() *FormHandler {
+		builds++
+		return NewForm(Required("City", String, INPUT()))
+	})
+	f := NewForm(Optional("Addresses", def, LEGEND()))
+
+	builds = 0
+	f.Describe()
+	f.JSONSchema()
+	f.OpenAPIRequestBody("")
+	if builds != 0 {
+		err(t, "the description should use the prototype", builds, 0)
+	}
+
+	f.ParseFormValues(map[string][]string{"Addresses[0].City": {"Duckburg"}, "Addresses[1].City": {"Entenhausen"}})
+	// the two entries and the template for new entries
+	if builds != 3 {
+		err(t, "only the submitted entries should be built", builds, 3)
+	}
+}
+
+func TestCollectionSchema(t *testing.T) {
+	b, _ := json.Marshal(newCollectionForm().JSONSchema().Properties["Addresses"])
+	shouldbe := `{"type":"array","items":{"type":"object","properties":{"City":{"type":"string"},"Zip":{"type":"integer","minimum":1000}},"required":["City"]},"minItems":1,"maxItems":2}`
+	if string(b) != shouldbe {
+		err(t, "incorrect schema", string(b), shouldbe)
+	}
+}
+
+func TestCollectionSetValues(t *testing.T) {
+	f := newCollectionForm()
+	f.SetValuesFrom(struct{ Addresses []Address }{[]Address{{City: "Duckburg"}, {City: "Bonn"}}})
+
+	if in := f.Any(h.Attr("name", "Addresses[1].City")); in == nil || in.Attribute("value") != "Bonn" {
+		err(t, "incorrect pre-filled entries", f.String(), `Addresses[1].City with value Bonn`)
+	}
+}
@@ -0,0 +1,95 @@
+package goform
+
+import (
+	h "github.com/metakeule/goh4"
+	"strconv"
+)
+
+// sets the html5 constraint attributes that correspond to the Validators of the
+// Field on its inner form field, so that browsers validate the same rules.
+// Rules for array Fields are not reflected, since their elements are not
+// entered into separate inputs (except for emails that may be separated by commas).
+func (ø *Field) setConstraintInfos() {
+	if ø.Type == Collection {
+		// MinItems and MaxItems limit the entries
+		ø.setCollectionInfos()
+		return
+	}
+	fs := ø.Element.Fields()
+	if len(fs) == 0 {
+		return
+	}
+	el := fs[0]
+	isInput := el.Tag() == "input"
+
+	for _, v := range ø.Validators {
+		switch r := v.(type) {
+		case MinRule:
+			if isInput && (ø.Type == Int || ø.Type == Float) {
+				ø.setNumberType(el)
+				el.Add(h.Attr("min", formatFloat(r.Min)))
+			}
+		case MaxRule:
+			if isInput && (ø.Type == Int || ø.Type == Float) {
+				ø.setNumberType(el)
+				el.Add(h.Attr("max", formatFloat(r.Max)))
+			}
+		case MinLengthRule:
+			if ø.Type == String {
+				el.Add(h.Attr("minlength", strconv.Itoa(r.Length)))
+			}
+		case MaxLengthRule:
+			if ø.Type == String {
+				el.Add(h.Attr("maxlength", strconv.Itoa(r.Length)))
+			}
+		case PatternRule:
+			if isInput && ø.Type == String {
+				el.Add(h.Attr("pattern", r.Expr))
+			}
+		case EmailRule:
+			if isInput && isTextInput(el) {
+				switch ø.Type {
+				case String:
+					el.Add(h.Attr("type", "email"))
+				case StringArray:
+					// the emails are submitted as one value, see multipleEmails
+					el.Add(h.Attr("type", "email", "multiple", "multiple"))
+				}
+			}
+		case URLRule:
+			if isInput && isTextInput(el) && ø.Type == String {
+				el.Add(h.Attr("type", "url"))
+			}
+		}
+	}
+}
+
+// reports if the input of the StringArray Field takes several emails, see Email
+func (ø *Field) multipleEmails() bool {
+	fs := ø.Element.Fields()
+	return ø.Type == StringArray && len(fs) == 1 && fs[0].Attribute("type") == "email" && fs[0].Attribute("multiple") != ""
+}
+
+// makes a text input a number input with a step matching the Type of the Field
+func (ø *Field) setNumberType(el *h.Element) {
+	if isTextInput(el) {
+		el.Add(h.Attr("type", "number"))
+	}
+	if el.Attribute("type") != "number" || el.Attribute("step") != "" {
+		return
+	}
+	if ø.Type == Int {
+		el.Add(h.Attr("step", "1"))
+	} else {
+		el.Add(h.Attr("step", "any"))
+	}
+}
+
+func isTextInput(el *h.Element) bool {
+	t := el.Attribute("type")
+	return t == "" || t == "text"
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
@@ -0,0 +1,245 @@
+package goform
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	h "github.com/metakeule/goh4"
+	. "github.com/metakeule/goh4/tag"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// the names under which the CSRF token is sent
+const (
+	CSRFTokenName  = "_csrf"        // the hidden input added by AddCSRFToken
+	CSRFHeaderName = "X-CSRF-Token" // the header for requests without form body, e.g. json
+)
+
+// the defaults for MemoryCSRFStore
+const (
+	DefaultCSRFTTL       = time.Hour // the lifetime of a token
+	DefaultMaxCSRFTokens = 100000
+)
+
+// ErrInvalidCSRFToken is the error if the CSRF token of a request is missing or
+// forged. The values of such a request are not parsed and a ValidationError with
+// CodeCSRF is added to the GeneralValidationErrors; check it with errors.Is.
+var ErrInvalidCSRFToken = errors.New("the form has expired or was not sent from this site, please submit it again")
+
+// CSRFStore issues and verifies the tokens that protect a form against cross site
+// request forgery. If FormHandler.CSRF is set, ParseRequest only accepts requests
+// with a valid token.
+type CSRFStore interface {
+	// Token returns a token for the client of the request, it may set cookies on w
+	Token(w http.ResponseWriter, r *http.Request) (string, error)
+	// Verify reports if the token was issued for the client of the request
+	Verify(r *http.Request, token string) bool
+}
+
+// ErrNoCSRFSecret is returned by CookieCSRFStore.Token if the store has no Secret
+var ErrNoCSRFSecret = errors.New("CookieCSRFStore needs a Secret")
+
+// CookieCSRFStore is a CSRFStore that implements the signed double submit pattern:
+// a random value, signed with HMAC-SHA256 of the Secret, is kept in a cookie of the
+// client and the token must equal it. Since a foreign site can't read the cookie,
+// it can't send the token. If Session is set, the signature includes the session
+// of the client, so that the token is only valid for it. Session is needed to
+// protect against attackers that can set cookies, e.g. from a subdomain: without
+// it they can get a valid pair of cookie and token from the site and plant it in
+// the browser of the victim.
+type CookieCSRFStore struct {
+	Secret  []byte                       // the key of the signature, must not be empty
+	Session func(r *http.Request) string // identifies the session of the client, nil means any session
+	Name    string                       // the name of the cookie, "" means CSRFTokenName
+	Path    string                       // the path of the cookie, "" means "/"
+	Secure  bool                         // if the cookie is only sent via https
+	MaxAge  int                          // the lifetime of the cookie in seconds, 0 means until the browser is closed
+}
+
+func (ø CookieCSRFStore) Token(w http.ResponseWriter, r *http.Request) (string, error) {
+	if len(ø.Secret) == 0 {
+		return "", ErrNoCSRFSecret
+	}
+	if c, err := r.Cookie(ø.name()); err == nil && ø.valid(r, c.Value) {
+		return c.Value, nil
+	}
+	nonce, err := randomToken()
+	if err != nil {
+		return "", err
+	}
+	token := nonce + "." + ø.sign(r, nonce)
+	path := ø.Path
+	if path == "" {
+		path = "/"
+	}
+	http.SetCookie(w, &http.Cookie{
+		Name:     ø.name(),
+		Value:    token,
+		Path:     path,
+		MaxAge:   ø.MaxAge,
+		Secure:   ø.Secure,
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	})
+	return token, nil
+}
+
+func (ø CookieCSRFStore) Verify(r *http.Request, token string) bool {
+	c, err := r.Cookie(ø.name())
+	if err != nil || !ø.valid(r, c.Value) {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(c.Value), []byte(token)) == 1
+}
+
+// reports if the value of the cookie is signed for the session of the request
+func (ø CookieCSRFStore) valid(r *http.Request, value string) bool {
+	i := strings.LastIndex(value, ".")
+	if len(ø.Secret) == 0 || i <= 0 {
+		return false
+	}
+	return hmac.Equal([]byte(value[i+1:]), []byte(ø.sign(r, value[:i])))
+}
+
+// signs the nonce together with the session of the request. The nonce contains
+// no dot, so that it can't be confused with the session.
+func (ø CookieCSRFStore) sign(r *http.Request, nonce string) string {
+	mac := hmac.New(sha256.New, ø.Secret)
+	mac.Write([]byte(nonce + "."))
+	if ø.Session != nil {
+		mac.Write([]byte(ø.Session(r)))
+	}
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func (ø CookieCSRFStore) name() string {
+	if ø.Name == "" {
+		return CSRFTokenName
+	}
+	return ø.Name
+}
+
+// MemoryCSRFStore is a CSRFStore that keeps the issued tokens in memory.
+// Each token can be verified once and expires after the TTL, so the form must get
+// a new token each time it is rendered. If Session is set, a token is only valid
+// for the session it was issued for. If there are more than MaxTokens tokens,
+// the oldest ones are removed. The zero value is ready to use and it is safe for
+// concurrent use.
+type MemoryCSRFStore struct {
+	Session   func(r *http.Request) string // identifies the session of the client, nil means any session
+	TTL       time.Duration                // the lifetime of a token, 0 means DefaultCSRFTTL
+	MaxTokens int                          // the maximal number of kept tokens, 0 means DefaultMaxCSRFTokens
+	mu        sync.Mutex
+	tokens    map[string]memoryCSRFToken
+	keys      []string // the tokens in the order they were issued
+}
+
+type memoryCSRFToken struct {
+	session string
+	expires time.Time
+}
+
+func NewMemoryCSRFStore(session func(r *http.Request) string) *MemoryCSRFStore {
+	return &MemoryCSRFStore{Session: session, tokens: map[string]memoryCSRFToken{}}
+}
+
+func (ø *MemoryCSRFStore) Token(w http.ResponseWriter, r *http.Request) (string, error) {
+	token, err := randomToken()
+	if err != nil {
+		return "", err
+	}
+	ttl := ø.TTL
+	if ttl <= 0 {
+		ttl = DefaultCSRFTTL
+	}
+	now := time.Now()
+	ø.mu.Lock()
+	defer ø.mu.Unlock()
+	if ø.tokens == nil {
+		ø.tokens = map[string]memoryCSRFToken{}
+	}
+	ø.removeOld(now)
+	ø.tokens[token] = memoryCSRFToken{session: ø.session(r), expires: now.Add(ttl)}
+	ø.keys = append(ø.keys, token)
+	return token, nil
+}
+
+// removes the oldest tokens until one is found that is neither verified nor expired
+// and there is room for a new token. Since all tokens live equally long, the tokens
+// after it are not expired either.
+func (ø *MemoryCSRFStore) removeOld(now time.Time) {
+	max := ø.MaxTokens
+	if max <= 0 {
+		max = DefaultMaxCSRFTokens
+	}
+	for len(ø.keys) > 0 {
+		t, ok := ø.tokens[ø.keys[0]]
+		if ok && len(ø.keys) < max && now.Before(t.expires) {
+			return
+		}
+		delete(ø.tokens, ø.keys[0])
+		ø.keys = ø.keys[1:]
+	}
+}
+
+func (ø *MemoryCSRFStore) Verify(r *http.Request, token string) bool {
+	ø.mu.Lock()
+	defer ø.mu.Unlock()
+	t, ok := ø.tokens[token]
+	if !ok {
+		return false
+	}
+	delete(ø.tokens, token)
+	return time.Now().Before(t.expires) && t.session == ø.session(r)
+}
+
+func (ø *MemoryCSRFStore) session(r *http.Request) string {
+	if ø.Session == nil {
+		return ""
+	}
+	return ø.Session(r)
+}
+
+func randomToken() (string, error) {
+	rnd := make([]byte, 32)
+	if _, err := rand.Read(rnd); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(rnd), nil
+}
+
+// AddCSRFToken adds a hidden input with a token of the CSRF store of the form for
+// the client of the request. It should be called right before the form is rendered,
+// i.e. after ParseRequest, since the Element of a Wizard is rebuilt on each step.
+func (ø *FormHandler) AddCSRFToken(w http.ResponseWriter, r *http.Request) (el *h.Element, err error) {
+	token, err := ø.CSRF.Token(w, r)
+	if err != nil {
+		return nil, err
+	}
+	el = INPUT(h.Attr("type", "hidden", "name", CSRFTokenName, "value", token))
+	ø.Element.Add(el)
+	return
+}
+
+// checks the CSRF token of the request, if the form has a CSRF store.
+// The token is taken from the header CSRFHeaderName or the body of the parsed form.
+func (ø *FormHandler) verifyCSRF(r *http.Request) error {
+	if ø.CSRF == nil {
+		return nil
+	}
+	token := r.Header.Get(CSRFHeaderName)
+	if token == "" {
+		token = r.PostForm.Get(CSRFTokenName)
+	}
+	if token == "" || !ø.CSRF.Verify(r, token) {
+		ø.AddValidationError(&ValidationError{Code: CodeCSRF, Message: ErrInvalidCSRFToken.Error(), err: ErrInvalidCSRFToken})
+		return ø.formErrors()
+	}
+	return nil
+}
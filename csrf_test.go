@@ -0,0 +1,232 @@
+package goform
+
+import (
+	"errors"
+	h "github.com/metakeule/goh4"
+	. "github.com/metakeule/goh4/tag"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+var csrfSecret = []byte("secret")
+
+func newCSRFTestForm(store CSRFStore, ran *bool) *FormHandler {
+	f := NewForm(Required("Name", String, INPUT()), store)
+	f.Action = func(*FormHandler) error {
+		*ran = true
+		return nil
+	}
+	return f
+}
+
+func csrfPost(vals url.Values, cookies ...*http.Cookie) *http.Request {
+	r := httptest.NewRequest("POST", "/", strings.NewReader(vals.Encode()))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	for _, c := range cookies {
+		r.AddCookie(c)
+	}
+	return r
+}
+
+func TestCSRFCookieStore(t *testing.T) {
+	var ran bool
+	f := newCSRFTestForm(CookieCSRFStore{Secret: csrfSecret}, &ran)
+	w := httptest.NewRecorder()
+	if _, e := f.AddCSRFToken(w, httptest.NewRequest("GET", "/", nil)); e != nil {
+		t.Fatalf("unexpected error: %s", e)
+	}
+
+	input := f.Any(h.Attr("name", CSRFTokenName))
+	cookies := w.Result().Cookies()
+	if input == nil || len(cookies) != 1 || cookies[0].Value != input.Attribute("value") {
+		t.Fatalf("the token should be rendered and set as cookie: %s %v", f.String(), cookies)
+	}
+	token := input.Attribute("value")
+
+	if e := f.ParseRequest(csrfPost(url.Values{"Name": {"Donald"}, CSRFTokenName: {token}}, cookies[0])); e != nil || !ran {
+		err(t, "a valid token should be accepted", e, nil)
+	}
+
+	// the token of an existing cookie is reused
+	w = httptest.NewRecorder()
+	r := httptest.NewRequest("GET", "/", nil)
+	r.AddCookie(cookies[0])
+	if again, _ := (CookieCSRFStore{Secret: csrfSecret}).Token(w, r); again != token || len(w.Result().Cookies()) != 0 {
+		err(t, "the token of the cookie should be reused", again, token)
+	}
+
+	tests := map[string]*http.Request{
+		"missing token":  csrfPost(url.Values{"Name": {"Donald"}}, cookies[0]),
+		"forged token":   csrfPost(url.Values{"Name": {"Donald"}, CSRFTokenName: {"forged"}}, cookies[0]),
+		"missing cookie": csrfPost(url.Values{"Name": {"Donald"}, CSRFTokenName: {token}}),
+		"unsigned cookie": csrfPost(url.Values{"Name": {"Donald"}, CSRFTokenName: {"forged"}},
+			&http.Cookie{Name: CSRFTokenName, Value: "forged"}),
+		"foreign signature": csrfPost(url.Values{"Name": {"Donald"}, CSRFTokenName: {"forged.sig"}},
+			&http.Cookie{Name: CSRFTokenName, Value: "forged.sig"}),
+	}
+	for name, r := range tests {
+		ran = false
+		var parsed bool
+		f = newCSRFTestForm(CookieCSRFStore{Secret: csrfSecret}, &ran)
+		f.BeforeParsing = func(*FormHandler) { parsed = true }
+		e := f.ParseRequest(r)
+		if !errors.Is(e, ErrInvalidCSRFToken) || len(f.GeneralValidationErrors) != 1 || !errors.Is(f.GeneralValidationErrors[0], ErrInvalidCSRFToken) {
+			err(t, name+" should be an ErrInvalidCSRFToken", e, ErrInvalidCSRFToken)
+		}
+		if ran || parsed || f.IsFilledField(f.Field("Name")) {
+			err(t, name+" should not be parsed", f.FilledFields, []string{})
+		}
+	}
+}
+
+func TestCSRFCookieStoreSession(t *testing.T) {
+	store := CookieCSRFStore{Secret: csrfSecret, Session: func(r *http.Request) string { return r.Header.Get("Session") }}
+	get := httptest.NewRequest("GET", "/", nil)
+	get.Header.Set("Session", "donald")
+	w := httptest.NewRecorder()
+	token, _ := store.Token(w, get)
+	cookie := w.Result().Cookies()[0]
+
+	r := csrfPost(url.Values{}, cookie)
+	r.Header.Set("Session", "donald")
+	if !store.Verify(r, token) {
+		err(t, "the token should be valid for its session", false, true)
+	}
+
+	r.Header.Set("Session", "daisy")
+	if store.Verify(r, token) {
+		err(t, "the token should not be valid for another session", true, false)
+	}
+
+	// a cookie of another session is replaced
+	w = httptest.NewRecorder()
+	get = httptest.NewRequest("GET", "/", nil)
+	get.Header.Set("Session", "daisy")
+	get.AddCookie(cookie)
+	if again, _ := store.Token(w, get); again == token || len(w.Result().Cookies()) != 1 {
+		err(t, "a cookie of another session should be replaced", again, "a new token")
+	}
+
+	if _, e := (CookieCSRFStore{}).Token(httptest.NewRecorder(), get); e != ErrNoCSRFSecret {
+		err(t, "a store without Secret should return an error", e, ErrNoCSRFSecret)
+	}
+}
+
+func TestCSRFHeader(t *testing.T) {
+	var ran bool
+	f := newCSRFTestForm(CookieCSRFStore{Secret: csrfSecret, Name: "token"}, &ran)
+	w := httptest.NewRecorder()
+	token, _ := f.CSRF.Token(w, httptest.NewRequest("GET", "/", nil))
+
+	r := httptest.NewRequest("POST", "/", strings.NewReader(`{"Name":"Donald"}`))
+	r.Header.Set("Content-Type", "application/json")
+	r.Header.Set(CSRFHeaderName, token)
+	r.AddCookie(w.Result().Cookies()[0])
+	if e := f.ParseRequest(r); e != nil || !ran {
+		err(t, "the token of the header should be accepted", e, nil)
+	}
+
+	f = newCSRFTestForm(CookieCSRFStore{Secret: csrfSecret, Name: "token"}, &ran)
+	f.Locale = "de"
+	r = httptest.NewRequest("POST", "/", strings.NewReader(`{"Name":"Donald"}`))
+	r.Header.Set("Content-Type", "application/json")
+	r.AddCookie(w.Result().Cookies()[0])
+	f.ParseRequest(r)
+	doc := f.ErrorDocument()
+	if len(doc.General) != 1 || doc.General[0].Code != CodeCSRF || doc.General[0].Message != Messages["de"][CodeCSRF] {
+		err(t, "the error should be translated", doc.General, Messages["de"][CodeCSRF])
+	}
+}
+
+func TestCSRFMemoryStore(t *testing.T) {
+	session := func(r *http.Request) string { return r.Header.Get("Session") }
+	store := NewMemoryCSRFStore(session)
+	get := httptest.NewRequest("GET", "/", nil)
+	get.Header.Set("Session", "donald")
+	token, _ := store.Token(httptest.NewRecorder(), get)
+
+	other := httptest.NewRequest("POST", "/", nil)
+	other.Header.Set("Session", "daisy")
+	if store.Verify(other, token) {
+		err(t, "the token should not be valid for another session", true, false)
+	}
+
+	token, _ = store.Token(httptest.NewRecorder(), get)
+	var ran bool
+	f := newCSRFTestForm(store, &ran)
+	r := csrfPost(url.Values{"Name": {"Donald"}, CSRFTokenName: {token}})
+	r.Header.Set("Session", "donald")
+	if e := f.ParseRequest(r); e != nil || !ran {
+		err(t, "the token should be valid for its session", e, nil)
+	}
+
+	if store.Verify(r, token) {
+		err(t, "the token should only be valid once", true, false)
+	}
+
+	store.tokens["expired"] = memoryCSRFToken{session: "donald"}
+	if store.Verify(r, "expired") {
+		err(t, "an expired token should be invalid", true, false)
+	}
+}
+
+func TestCSRFMemoryStoreZeroValue(t *testing.T) {
+	store := &MemoryCSRFStore{TTL: time.Millisecond}
+	r := httptest.NewRequest("GET", "/", nil)
+	old, e := store.Token(httptest.NewRecorder(), r)
+	if e != nil {
+		t.Fatalf("unexpected error: %s", e)
+	}
+	time.Sleep(2 * time.Millisecond)
+	store.TTL = time.Hour
+	token, _ := store.Token(httptest.NewRecorder(), r)
+	if _, kept := store.tokens[old]; kept || len(store.keys) != 1 {
+		err(t, "expired tokens should be removed", len(store.keys), 1)
+	}
+	if !store.Verify(r, token) {
+		err(t, "the token should be valid", false, true)
+	}
+}
+
+func TestCSRFMemoryStoreLimit(t *testing.T) {
+	store := &MemoryCSRFStore{MaxTokens: 2}
+	r := httptest.NewRequest("GET", "/", nil)
+	first, _ := store.Token(httptest.NewRecorder(), r)
+	second, _ := store.Token(httptest.NewRecorder(), r)
+	store.Token(httptest.NewRecorder(), r)
+
+	if len(store.tokens) != 2 {
+		err(t, "incorrect number of tokens", len(store.tokens), 2)
+	}
+
+	if store.Verify(r, first) {
+		err(t, "the oldest token should be removed", true, false)
+	}
+
+	if !store.Verify(r, second) {
+		err(t, "the newer token should be kept", false, true)
+	}
+}
+
+func TestCSRFWizard(t *testing.T) {
+	var saved map[string]interface{}
+	store := NewMemoryCSRFStore(nil)
+	w := newTestWizard(SignedState{Secret: []byte("secret")}, &saved)
+	w.CSRF = store
+	w.AddCSRFToken(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+
+	if e := submitStep(w, url.Values{"Name": {"Donald"}}); !errors.Is(e, ErrInvalidCSRFToken) || w.Current != 0 {
+		err(t, "a step without token should be rejected", e, ErrInvalidCSRFToken)
+	}
+
+	w = nextRequest(w, &saved)
+	w.CSRF = store
+	token, _ := w.AddCSRFToken(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+	if e := submitStep(w, url.Values{"Name": {"Donald"}, CSRFTokenName: {token.Attribute("value")}}); e != nil || w.Current != 1 {
+		err(t, "a step with a valid token should be accepted", e, nil)
+	}
+}
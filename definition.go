@@ -0,0 +1,93 @@
+package goform
+
+import (
+	h "github.com/metakeule/goh4"
+)
+
+// FormDefinition is the definition of one form that can be shared between
+// goroutines, e.g. between concurrent http handlers. Since a FormHandler holds the
+// parsed values, the errors and the rendered html of one submission, it must not
+// be shared; instead every request gets its own Submission. A Submission is a
+// completely new FormHandler, including its Fields and html, there is no state
+// that is shared between Submissions.
+// The definition keeps one form as prototype, that is only read: it describes the
+// form (Describe, JSONSchema, OpenAPIRequestBody) and tells which entries of a
+// Collection are submitted, so that only the submitted entries are built.
+type FormDefinition struct {
+	build     func() *FormHandler
+	prototype *FormHandler
+}
+
+// NewFormDefinition creates a definition from a function that builds the form,
+// e.g. by calling NewForm and setting the hooks. The function is called once
+// for every Submission, so it must create all Fields, Groups and html elements
+// of the form each time, instead of referring to ones created outside of it.
+// It is called twice immediately, for the prototype and to detect errors in the
+// definition, and panics if both forms share a Field, Group or element.
+func NewFormDefinition(build func() *FormHandler) (ø *FormDefinition) {
+	ø = &FormDefinition{build: build, prototype: build()}
+	first := ø.prototype.objects()
+	for obj, name := range build().objects() {
+		if _, shared := first[obj]; shared {
+			panic("form definition: " + name + " is shared between submissions, it must be created by the build function")
+		}
+	}
+	return
+}
+
+// returns all Fields, Groups and elements of the form that are changed
+// by a submission, mapped to a description
+func (ø *FormHandler) objects() map[interface{}]string {
+	objs := map[interface{}]string{ø.Element: "the element of the form"}
+	for name, field := range ø.Fields {
+		objs[field] = "Field " + name
+		objs[field.Element] = "the element of Field " + name
+	}
+	var walk func(order []h.Stringer)
+	walk = func(order []h.Stringer) {
+		for _, s := range order {
+			switch v := s.(type) {
+			case *Group:
+				objs[v] = "Group " + v.Name
+				walk(v.Order)
+			case *h.Element:
+				objs[v] = "the html element " + v.Tag()
+			}
+		}
+	}
+	walk(ø.Order)
+	return objs
+}
+
+// returns true if the source has values for any Field of the entry with the
+// given prefix, e.g. "Addresses[0].", by asking for the Fields of the prototype
+// under their path
+func (ø *FormDefinition) hasValues(src ValueSource, prefix string) bool {
+	for _, f := range ø.prototype.orderedFields() {
+		if f.Type == Collection {
+			if f.Definition.hasValues(src, prefix+f.Name+"[0].") {
+				return true
+			}
+			continue
+		}
+		probe := *f
+		probe.Name = prefix + f.Name
+		if _, ok := src.Values(&probe); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// Submission is the state of one submission of a form: the parsed values,
+// the errors and the rendered html. It is not safe for concurrent use.
+type Submission struct {
+	*FormHandler
+	Definition *FormDefinition
+}
+
+// NewSubmission creates a new, empty Submission of the form by calling the
+// build function of the definition
+func (ø *FormDefinition) NewSubmission() *Submission {
+	return &Submission{FormHandler: ø.build(), Definition: ø}
+}
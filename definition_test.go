@@ -0,0 +1,71 @@
+package goform
+
+import (
+	"fmt"
+	. "github.com/metakeule/goh4/tag"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// run with -race
+func TestFormDefinitionConcurrent(t *testing.T) {
+	def := NewFormDefinition(func() *FormHandler {
+		f := NewForm(
+			Required("Name", String, LABEL(INPUT())),
+			Required("Age", Int, LABEL(INPUT())),
+		)
+		f.Validation = func(ø *FormHandler) {
+			if ø.Ints[ø.Field("Age")] > 150 {
+				ø.AddFieldError(ø.Field("Age"), fmt.Errorf("too old"))
+			}
+		}
+		return f
+	})
+
+	var wg sync.WaitGroup
+	for i := 1; i <= 50; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			s := def.NewSubmission()
+			age := strconv.Itoa(i * 5)
+			e := s.Parse(map[string]string{"Name": "Donald", "Age": age})
+			s.RenderSubmission()
+
+			if s.Ints[s.Field("Age")] != i*5 {
+				err(t, "incorrect Age", s.Ints[s.Field("Age")], i*5)
+			}
+
+			if tooOld := i*5 > 150; tooOld != (e != nil) {
+				err(t, "incorrect error for age "+age, e, tooOld)
+			}
+
+			if !strings.Contains(s.String(), `value="`+age+`"`) {
+				err(t, "rendered html should contain the own age", s.String(), age)
+			}
+		}(i)
+	}
+	wg.Wait()
+}
+
+func TestFormDefinitionShared(t *testing.T) {
+	name := Required("Name", String, INPUT())
+	title := P("Title")
+
+	tests := map[string]func() *FormHandler{
+		"Field":   func() *FormHandler { return NewForm(name) },
+		"element": func() *FormHandler { return NewForm(title, Required("Name", String, INPUT())) },
+	}
+	for what, build := range tests {
+		func() {
+			defer func() {
+				if recover() == nil {
+					err(t, "a shared "+what+" should panic", nil, "panic")
+				}
+			}()
+			NewFormDefinition(build)
+		}()
+	}
+}
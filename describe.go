@@ -0,0 +1,216 @@
+package goform
+
+import (
+	h "github.com/metakeule/goh4"
+	"github.com/metakeule/typeconverter"
+	"html"
+	"reflect"
+	"regexp"
+	"strings"
+)
+
+// the names of the Types in FieldDescriptions
+var TypeNames = map[Type]string{
+	Int:         "int",
+	String:      "string",
+	Float:       "float",
+	IntArray:    "int_array",
+	StringArray: "string_array",
+	FloatArray:  "float_array",
+	Map:         "map",
+	Struct:      "struct",
+	Fill:        "fill",
+	Bool:        "bool",
+	File:        "file",
+	FileArray:   "file_array",
+	Date:        "date",
+	Time:        "time",
+	DateTime:    "datetime",
+	Duration:    "duration",
+	Collection:  "collection",
+}
+
+// FormDescription is a json serializable description of a form, e.g. for
+// rendering it on the client
+type FormDescription struct {
+	Method  string            `json:"method"`
+	Enctype string            `json:"enctype"`
+	Items   []ItemDescription `json:"items"` // in the Order of the form
+}
+
+// ItemDescription is either a Field, a Group or a static html block added via AddHtml
+type ItemDescription struct {
+	Field *FieldDescription `json:"field,omitempty"`
+	Group *GroupDescription `json:"group,omitempty"`
+	Html  string            `json:"html,omitempty"`
+}
+
+// GroupDescription describes a Group and its items in Order
+type GroupDescription struct {
+	Name        string            `json:"name"`
+	Legend      string            `json:"legend"`
+	Collapsible bool              `json:"collapsible,omitempty"`
+	Open        bool              `json:"open,omitempty"`
+	Items       []ItemDescription `json:"items"`
+}
+
+// FieldDescription describes a Field
+type FieldDescription struct {
+	Name      string              `json:"name"`
+	Type      string              `json:"type"`                // see TypeNames
+	Widget    string              `json:"widget"`              // the tag of the first input: input, select or textarea, or collection
+	InputType string              `json:"inputType,omitempty"` // the type attribute of an input
+	Label     string              `json:"label,omitempty"`
+	Help      string              `json:"help,omitempty"` // see Help
+	Required  bool                `json:"required"`
+	Multiple  bool                `json:"multiple,omitempty"`
+	Options   []OptionDescription `json:"options,omitempty"`
+	Rules     []RuleDescription   `json:"rules,omitempty"`
+	Entries   *FormDescription    `json:"entries,omitempty"` // only for Collections, the form of the entries
+}
+
+// OptionDescription is an allowed value of a select or a Selection
+type OptionDescription struct {
+	Value string `json:"value"`
+	Text  string `json:"text"`
+}
+
+// RuleDescription is a validation rule of a Field. The Code is the code of
+// the ValidationError the rule produces, the Params are those of the error,
+// so that the messages of the Catalog can be used on the client as well.
+type RuleDescription struct {
+	Code   string `json:"code"`
+	Params Params `json:"params,omitempty"`
+}
+
+// Describe returns a description of the form, its Fields, Groups and static html in Order.
+// Labels are described in the Locale of the form if TranslateLabels was called before.
+func (ø *FormHandler) Describe() *FormDescription {
+	return &FormDescription{
+		Method:  ø.Element.Attribute("method"),
+		Enctype: ø.Element.Attribute("enctype"),
+		Items:   describeItems(ø.Order),
+	}
+}
+
+func describeItems(order []h.Stringer) []ItemDescription {
+	items := []ItemDescription{}
+	for _, s := range order {
+		switch v := s.(type) {
+		case *Field:
+			items = append(items, ItemDescription{Field: v.Describe()})
+		case *Group:
+			items = append(items, ItemDescription{Group: &GroupDescription{
+				Name:        v.Name,
+				Legend:      v.Legend,
+				Collapsible: v.Collapsible,
+				Open:        v.Open,
+				Items:       describeItems(v.Order),
+			}})
+		default:
+			items = append(items, ItemDescription{Html: s.String()})
+		}
+	}
+	return items
+}
+
+// Describe returns a description of the Field
+func (ø *Field) Describe() *FieldDescription {
+	d := &FieldDescription{
+		Name:     ø.Name,
+		Type:     TypeNames[ø.Type],
+		Required: ø.Required,
+		Rules:    ø.rules(),
+	}
+	if ø.Type == Collection {
+		d.Widget = "collection"
+		d.Entries = ø.Definition.prototype.Describe()
+		return d
+	}
+	fs := ø.Element.Fields()
+	d.Widget = fs[0].Tag()
+	d.InputType = fs[0].Attribute("type")
+	d.Multiple = fs[0].Attribute("multiple") != ""
+	if label := ø.Element.Any(h.Tag("label")); label != nil {
+		d.Label = labelText(label)
+	}
+	if help := ø.Element.Any(h.Id(ø.helpId())); help != nil {
+		d.Help = html.UnescapeString(help.InnerHtml())
+	}
+
+	if sel := ø.Element.Any(h.Tag("select")); sel != nil {
+		for _, opt := range sel.All(h.Tag("option")) {
+			val, hasVal := opt.Attributes()["value"]
+			if !hasVal {
+				val = opt.InnerHtml()
+			}
+			d.Options = append(d.Options, OptionDescription{val, html.UnescapeString(opt.InnerHtml())})
+		}
+	} else if ø.Selection != nil {
+		sel := reflect.ValueOf(ø.Selection)
+		for i := 0; i < sel.Len(); i++ {
+			var str string
+			typeconverter.Convert(sel.Index(i).Interface(), &str)
+			d.Options = append(d.Options, OptionDescription{str, str})
+		}
+	}
+	return d
+}
+
+var tagRegexp = regexp.MustCompile("<[^>]*>")
+
+// the text of a label without the inputs inside it
+func labelText(label *h.Element) string {
+	inner := label.InnerHtml()
+	for _, f := range label.Fields() {
+		inner = strings.Replace(inner, f.String(), "", 1)
+	}
+	return strings.TrimSpace(html.UnescapeString(tagRegexp.ReplaceAllString(inner, "")))
+}
+
+// the validation rules of the Field, see RuleDescription
+func (ø *Field) rules() (rules []RuleDescription) {
+	for _, v := range ø.Validators {
+		switch r := v.(type) {
+		case MinRule:
+			rules = append(rules, RuleDescription{CodeMin, Params{"min": r.Min}})
+		case MaxRule:
+			rules = append(rules, RuleDescription{CodeMax, Params{"max": r.Max}})
+		case MinLengthRule:
+			rules = append(rules, RuleDescription{CodeTooShort, Params{"min_length": r.Length}})
+		case MaxLengthRule:
+			rules = append(rules, RuleDescription{CodeTooLong, Params{"max_length": r.Length}})
+		case PatternRule:
+			rules = append(rules, RuleDescription{CodePattern, Params{"pattern": r.Expr}})
+		case MinItemsRule:
+			rules = append(rules, RuleDescription{CodeTooFewItems, Params{"min_items": r.Count}})
+		case MaxItemsRule:
+			rules = append(rules, RuleDescription{CodeTooManyItems, Params{"max_items": r.Count}})
+		case EmailRule:
+			rules = append(rules, RuleDescription{Code: CodeEmail})
+		case URLRule:
+			rules = append(rules, RuleDescription{Code: CodeURL})
+		case UUIDRule:
+			rules = append(rules, RuleDescription{Code: CodeUUID})
+		}
+	}
+	if !ø.MinTime.IsZero() {
+		rules = append(rules, RuleDescription{CodeTooEarly, Params{"min": ø.FormatTime(ø.MinTime)}})
+	}
+	if !ø.MaxTime.IsZero() {
+		rules = append(rules, RuleDescription{CodeTooLate, Params{"max": ø.FormatTime(ø.MaxTime)}})
+	}
+	if ø.MinDuration != 0 {
+		rules = append(rules, RuleDescription{CodeDurationTooShort, Params{"min": ø.MinDuration.String()}})
+	}
+	if ø.MaxDuration != 0 {
+		rules = append(rules, RuleDescription{CodeDurationTooLong, Params{"max": ø.MaxDuration.String()}})
+	}
+	if ø.MaxFileSize != 0 {
+		rules = append(rules, RuleDescription{CodeFileTooLarge, Params{"max_size": ø.MaxFileSize}})
+	}
+	if len(ø.MimeTypes) > 0 {
+		rules = append(rules, RuleDescription{CodeInvalidMimeType, Params{"mime_types": ø.MimeTypes}})
+	}
+	return
+}
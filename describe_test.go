@@ -0,0 +1,57 @@
+package goform
+
+import (
+	"encoding/json"
+	h "github.com/metakeule/goh4"
+	. "github.com/metakeule/goh4/tag"
+	"strings"
+	"testing"
+)
+
+func TestDescribe(t *testing.T) {
+	f := NewForm(
+		Validators(Required("Name", String, LABEL(h.Text("Your Name: "), INPUT())), MinLength(2)),
+		DIV(h.Text("something in between")),
+		Selection(Optional("Color", String, LABEL(h.Text("Color"), SELECT(OPTION(h.Text("Red")), OPTION(h.Text("Blue"))))), "r", "b"),
+		Selection(Optional("Size", Int, INPUT()), 1, 2),
+	)
+
+	d := f.Describe()
+
+	if len(d.Items) != 4 {
+		err(t, "incorrect number of items", len(d.Items), 4)
+		return
+	}
+
+	name := d.Items[0].Field
+	if name.Name != "Name" || name.Type != "string" || name.Widget != "input" || !name.Required {
+		err(t, "incorrect description of Name", name, "required string input")
+	}
+
+	if name.Label != "Your Name:" {
+		err(t, "incorrect label of Name", name.Label, "Your Name:")
+	}
+
+	if len(name.Rules) != 1 || name.Rules[0].Code != CodeTooShort || name.Rules[0].Params["min_length"] != 2 {
+		err(t, "incorrect rules of Name", name.Rules, "too_short with min_length 2")
+	}
+
+	if d.Items[1].Field != nil || d.Items[1].Html != "<div>something in between</div>" {
+		err(t, "incorrect static html", d.Items[1].Html, "<div>something in between</div>")
+	}
+
+	color := d.Items[2].Field
+	if color.Widget != "select" || len(color.Options) != 2 || color.Options[1] != (OptionDescription{"b", "Blue"}) {
+		err(t, "incorrect options of Color", color.Options, "r: Red, b: Blue")
+	}
+
+	size := d.Items[3].Field
+	if len(size.Options) != 2 || size.Options[0] != (OptionDescription{"1", "1"}) {
+		err(t, "incorrect options of Size", size.Options, "1, 2")
+	}
+
+	b, _ := json.Marshal(d)
+	if js := string(b); !strings.Contains(js, `"code":"too_short","params":{"min_length":2}`) {
+		err(t, "incorrect json", js, `rule with code and params`)
+	}
+}
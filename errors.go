@@ -0,0 +1,135 @@
+package goform
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// the codes of the ValidationErrors reported by goform
+const (
+	CodeRequired         = "required"
+	CodeNotInt           = "not_int"
+	CodeNotFloat         = "not_float"
+	CodeNotBool          = "not_bool"
+	CodeNotString        = "not_string"
+	CodeNotTime          = "not_time"
+	CodeNotDuration      = "not_duration"
+	CodeInvalidJSON      = "invalid_json"
+	CodeNotInSelection   = "not_in_selection"
+	CodeTooEarly         = "too_early"
+	CodeTooLate          = "too_late"
+	CodeDurationTooShort = "duration_too_short"
+	CodeDurationTooLong  = "duration_too_long"
+	CodeUnreadableFile   = "unreadable_file"
+	CodeFileTooLarge     = "file_too_large"
+	CodeInvalidMimeType  = "invalid_mime_type"
+	CodeMin              = "min"
+	CodeMax              = "max"
+	CodeTooShort         = "too_short"
+	CodeTooLong          = "too_long"
+	CodePattern          = "pattern"
+	CodeTooFewItems      = "too_few_items"
+	CodeTooManyItems     = "too_many_items"
+	CodeEmail            = "email"
+	CodeURL              = "url"
+	CodeUUID             = "uuid"
+	CodeCSRF             = "csrf"    // the general error for ErrInvalidCSRFToken
+	CodeInvalid          = "invalid" // used in ErrorDocuments for errors that are no ValidationErrors
+)
+
+// sentinel errors to check the error returned by Parse, ParseFormValues and
+// ParseRequest with errors.Is
+var (
+	ErrInvalid                 = errors.New("invalid form")              // there are Field errors or general validation errors
+	ErrFieldErrors             = errors.New("Field errors")              // there are Field errors
+	ErrGeneralValidationErrors = errors.New("general validation errors") // there are general validation errors
+)
+
+// Params are the parameters of a ValidationError
+type Params map[string]interface{}
+
+// ValidationError is a structured error for an invalid Field value
+type ValidationError struct {
+	Code    string      // a stable identifier of the kind of error, see the Code constants
+	Field   string      // the name of the Field, empty for general errors
+	Value   interface{} // the offending value, for parsing errors the raw submitted string
+	Params  Params      // the parameters of the violated rule, e.g. {"min": 3}
+	Message string      // a human readable description
+	err     error       // the sentinel error, if any, e.g. ErrInvalidCSRFToken
+}
+
+func (ø *ValidationError) Error() string { return ø.Message }
+
+// Unwrap returns the sentinel error of general errors, e.g. ErrInvalidCSRFToken
+func (ø *ValidationError) Unwrap() error { return ø.err }
+
+func newValidationError(field *Field, code string, value interface{}, params Params, format string, args ...interface{}) *ValidationError {
+	return &ValidationError{
+		Code:    code,
+		Field:   field.Name,
+		Value:   value,
+		Params:  params,
+		Message: fmt.Sprintf(format, args...),
+	}
+}
+
+// FormErrors is returned by Parse, ParseFormValues and ParseRequest if there
+// are Field errors or general validation errors. It matches ErrInvalid,
+// ErrFieldErrors and ErrGeneralValidationErrors with errors.Is and the
+// contained errors (e.g. a *ValidationError) with errors.As.
+type FormErrors struct {
+	FieldErrors map[string][]error // keyed by Field name, or by path for Collection entries (e.g. "Addresses[0].City")
+	General     []error
+}
+
+func (ø *FormErrors) Error() string {
+	switch {
+	case len(ø.FieldErrors) > 0 && len(ø.General) > 0:
+		return "Field errors and general validation errors"
+	case len(ø.FieldErrors) > 0:
+		return ErrFieldErrors.Error()
+	}
+	return ErrGeneralValidationErrors.Error()
+}
+
+func (ø *FormErrors) Is(target error) bool {
+	switch target {
+	case ErrInvalid:
+		return true
+	case ErrFieldErrors:
+		return len(ø.FieldErrors) > 0
+	case ErrGeneralValidationErrors:
+		return len(ø.General) > 0
+	}
+	return false
+}
+
+// Unwrap returns all contained errors, the Field errors sorted by Field name
+func (ø *FormErrors) Unwrap() []error {
+	names := []string{}
+	for name := range ø.FieldErrors {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	errs := []error{}
+	for _, name := range names {
+		errs = append(errs, ø.FieldErrors[name]...)
+	}
+	return append(errs, ø.General...)
+}
+
+// returns the current errors of the form as *FormErrors or nil if there are none
+func (ø *FormHandler) formErrors() error {
+	if len(ø.FieldErrors) == 0 && len(ø.GeneralValidationErrors) == 0 {
+		return nil
+	}
+	fe := &FormErrors{FieldErrors: map[string][]error{}, General: ø.GeneralValidationErrors}
+	for field, errs := range ø.FieldErrors {
+		for _, err := range errs {
+			key := errorKey(field, err)
+			fe.FieldErrors[key] = append(fe.FieldErrors[key], err)
+		}
+	}
+	return fe
+}
@@ -0,0 +1,61 @@
+package goform
+
+import (
+	"errors"
+	"fmt"
+	. "github.com/metakeule/goh4/tag"
+	"testing"
+)
+
+func TestParseErrors(t *testing.T) {
+	f := NewForm(
+		Required("Name", String, INPUT()),
+		Selection(Optional("Age", Int, SELECT(OPTION("18"), OPTION("21"))), 18, 21),
+		Optional("Height", Float, INPUT()),
+	)
+
+	e := f.Parse(map[string]string{"Age": "65", "Height": "tall"})
+
+	if !errors.Is(e, ErrInvalid) || !errors.Is(e, ErrFieldErrors) || errors.Is(e, ErrGeneralValidationErrors) {
+		err(t, "incorrect sentinel errors", e, ErrFieldErrors)
+	}
+
+	var fe *FormErrors
+	if !errors.As(e, &fe) || len(fe.FieldErrors) != 3 {
+		t.Fatalf("expected FormErrors for 3 fields, got %#v", e)
+	}
+
+	codes := map[string]string{"Name": CodeRequired, "Age": CodeNotInSelection, "Height": CodeNotFloat}
+	for name, code := range codes {
+		var ve *ValidationError
+		if !errors.As(fe.FieldErrors[name][0], &ve) || ve.Code != code || ve.Field != name {
+			err(t, "incorrect error for "+name, fe.FieldErrors[name][0], code)
+		}
+	}
+
+	var ve *ValidationError
+	if !errors.As(e, &ve) || ve.Field != "Age" {
+		err(t, "errors.As should find the first ValidationError by Field name", ve, "Age")
+	}
+
+	if ve.Value != 65 || fmt.Sprint(ve.Params["selection"]) != "[18 21]" {
+		err(t, "incorrect value and params", ve, "65 not in [18 21]")
+	}
+}
+
+func TestGeneralValidationErrors(t *testing.T) {
+	f := NewForm(Optional("Name", String, INPUT()))
+	f.Validation = func(ø *FormHandler) {
+		ø.AddValidationError(fmt.Errorf("not now"))
+	}
+
+	e := f.Parse(map[string]string{"Name": "Donald"})
+
+	if !errors.Is(e, ErrGeneralValidationErrors) || errors.Is(e, ErrFieldErrors) {
+		err(t, "incorrect sentinel errors", e, ErrGeneralValidationErrors)
+	}
+
+	if e.Error() != "general validation errors" {
+		err(t, "incorrect message", e.Error(), "general validation errors")
+	}
+}
@@ -0,0 +1,68 @@
+package goform
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// ErrorDocument is the json representation of the errors of a form:
+//
+//	{"fields":{"Age":[{"code":"not_int","message":"..."}]},"general":[{"code":"invalid","message":"..."}]}
+type ErrorDocument struct {
+	Fields  map[string][]ErrorEntry `json:"fields"`  // keyed by Field name, or by path for Collection entries
+	General []ErrorEntry            `json:"general"` // the GeneralValidationErrors
+}
+
+// ErrorEntry is the json representation of one error
+type ErrorEntry struct {
+	Code    string `json:"code"`
+	Message string `json:"message"` // translated to the Locale of the form
+	Params  Params `json:"params,omitempty"`
+}
+
+// ErrorDocument returns the current errors of the form in a serializable form
+func (ø *FormHandler) ErrorDocument() *ErrorDocument {
+	doc := &ErrorDocument{Fields: map[string][]ErrorEntry{}, General: []ErrorEntry{}}
+	for field, errs := range ø.FieldErrors {
+		for _, err := range errs {
+			key := errorKey(field, err)
+			doc.Fields[key] = append(doc.Fields[key], ø.errorEntry(err))
+		}
+	}
+	for _, err := range ø.GeneralValidationErrors {
+		doc.General = append(doc.General, ø.errorEntry(err))
+	}
+	return doc
+}
+
+func (ø *FormHandler) errorEntry(err error) ErrorEntry {
+	e := ErrorEntry{Code: CodeInvalid, Message: ø.ErrorMessage(err)}
+	if ve, ok := err.(*ValidationError); ok {
+		e.Code = ve.Code
+		e.Params = ve.Params
+	}
+	return e
+}
+
+// ErrorsJSON returns the ErrorDocument of the form as json
+func (ø *FormHandler) ErrorsJSON() ([]byte, error) {
+	return json.Marshal(ø.ErrorDocument())
+}
+
+// ErrorsHandler returns a http.Handler that writes the ErrorsJSON of the form
+// with the given status code (0 means 422 Unprocessable Entity)
+func (ø *FormHandler) ErrorsHandler(status int) http.Handler {
+	if status == 0 {
+		status = http.StatusUnprocessableEntity
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, err := ø.ErrorsJSON()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		w.WriteHeader(status)
+		w.Write(b)
+	})
+}
@@ -0,0 +1,44 @@
+package goform
+
+import (
+	"encoding/json"
+	"fmt"
+	. "github.com/metakeule/goh4/tag"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestErrorsHandler(t *testing.T) {
+	f := NewForm(
+		Required("Name", String, INPUT()),
+		Validators(Optional("Age", Int, INPUT()), Min(18)),
+	)
+	f.Validation = func(ø *FormHandler) {
+		ø.AddValidationError(fmt.Errorf("not now"))
+	}
+	_ = f.Parse(map[string]string{"Age": "12"})
+
+	w := httptest.NewRecorder()
+	f.ErrorsHandler(0).ServeHTTP(w, httptest.NewRequest("POST", "/", nil))
+
+	if w.Code != 422 {
+		err(t, "incorrect status", w.Code, 422)
+	}
+
+	var doc ErrorDocument
+	if e := json.Unmarshal(w.Body.Bytes(), &doc); e != nil {
+		t.Fatalf("invalid json %s: %s", w.Body.String(), e)
+	}
+
+	if len(doc.Fields["Name"]) != 1 || doc.Fields["Name"][0].Code != CodeRequired {
+		err(t, "incorrect errors for Name", doc.Fields["Name"], CodeRequired)
+	}
+
+	if len(doc.Fields["Age"]) != 1 || doc.Fields["Age"][0].Code != CodeMin || doc.Fields["Age"][0].Params["min"] != 18.0 {
+		err(t, "incorrect errors for Age", doc.Fields["Age"], CodeMin)
+	}
+
+	if len(doc.General) != 1 || doc.General[0].Code != CodeInvalid || doc.General[0].Message != "not now" {
+		err(t, "incorrect general errors", doc.General, "not now")
+	}
+}
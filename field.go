@@ -1,8 +1,8 @@
 package goform
 
 import (
-	"fmt"
 	h "github.com/metakeule/goh4"
+	"time"
 )
 
 type Field struct {
@@ -10,36 +10,82 @@ type Field struct {
 	Name        string
 	Type        Type
 	Required    bool
-	Constructor Constructor // only for struct Fields, should return a pointer to a struct
-	Selection   interface{} // if only certain values are allowed, should be an array of things that are of the same type as value
+	Constructor Constructor     // only for struct Fields, should return a pointer to a struct
+	Selection   interface{}     // if only certain values are allowed, should be an array of things that are of the same type as value
+	MaxFileSize int64           // only for File and FileArray Fields, maximal size in bytes of each uploaded file (0 means no limit)
+	MimeTypes   []string        // only for File and FileArray Fields, allowed detected content types, e.g. "image/png" or "image/*"
+	Layout      string          // only for Date, Time and DateTime Fields, overwrites the layout of DefaultLayouts
+	MinTime     time.Time       // only for Date, Time and DateTime Fields, the earliest allowed value (zero means no limit)
+	MaxTime     time.Time       // only for Date, Time and DateTime Fields, the latest allowed value (zero means no limit)
+	MinDuration time.Duration   // only for Duration Fields, the shortest allowed value (0 means no limit)
+	MaxDuration time.Duration   // only for Duration Fields, the longest allowed value (0 means no limit)
+	Validators  []Validator     // run by FormHandler.Validate if the Field is filled
+	Locale      string          // overwrites the Locale of the form for parsing and formatting numbers and dates
+	Definition  *FormDefinition // only for Collection Fields, the form of the entries
+	AddText     string          // only for Collection Fields, the text of the add button, "" means "Add"
+	RemoveText  string          // only for Collection Fields, the text of the remove buttons, "" means "Remove"
+	theme       Theme           // the Theme whose classes the elements have
+	entries     []*FormHandler  // only for Collection Fields, the rendered entries
+	template    *FormHandler    // only for Collection Fields, the entry that is added in the browser
+	errorList   *h.Element      // the error messages added by RenderSubmission
 }
 
 // sets the infos of the inner Field tag
 func (ø *Field) setFieldInfos() {
+	if ø.Type == Collection {
+		if ø.theme == nil {
+			ø.theme = PlainTheme{}
+		}
+		ø.setCollectionInfos()
+		return
+	}
 	fs := ø.Element.Fields()
 	if len(fs) == 0 {
 		panic("got no form Field in " + ø.Element.String())
 	}
-	fs[0].Add(h.Class("field"), h.Id(ø.Name), h.Attr("name", ø.Name))
+	fs[0].Add(h.Id(ø.Name), h.Attr("name", ø.Name))
+	// further inputs of the same Field, e.g. radio buttons
+	for _, f := range fs[1:] {
+		f.Add(h.Attr("name", ø.Name))
+	}
+	switch ø.Type {
+	case File:
+		fs[0].Add(h.Attr("type", "file"))
+	case FileArray:
+		fs[0].Add(h.Attr("type", "file", "multiple", "multiple"))
+	}
+	// explicitly typed inputs (e.g. text inputs for LocaleLayouts) are kept
+	if fs[0].Tag() == "input" && fs[0].Attribute("type") == "" {
+		switch ø.Type {
+		case Date:
+			fs[0].Add(h.Attr("type", "date"))
+		case Time:
+			fs[0].Add(h.Attr("type", "time"))
+		case DateTime:
+			fs[0].Add(h.Attr("type", "datetime-local"))
+		}
+	}
+	ø.setConstraintInfos()
 	if ø.Required {
 		fs[0].Add(h.Attr("required", "required"))
-		fs[0].AddClass(h.Class("required"))
 	}
 	ø.setLabelInfos()
+	if ø.theme == nil {
+		ø.theme = PlainTheme{}
+	}
+	ø.setTheme(ø.theme)
 }
 
 func (ø *Field) setLabelInfos() {
 	label := ø.Element.Any(h.Tag("label"))
 	if label != nil {
 		label.Add(h.Attr("for", ø.Name))
-		if ø.Required {
-			label.Add(h.Class("required"))
-		}
 	}
 }
 
+// CheckAllowed adds an error if the value of a filled Field is not in its Selection
 func (ø *Field) CheckAllowed(form *FormHandler) {
-	if ø.Selection == nil {
+	if ø.Selection == nil || !form.IsFilledField(ø) {
 		return
 	}
 
@@ -48,19 +94,19 @@ func (ø *Field) CheckAllowed(form *FormHandler) {
 		a := ø.Selection.([]int)
 		val := form.Ints[ø]
 		if !ø.hasInt(a, val) {
-			form.AddFieldError(ø, fmt.Errorf("%#v not in %+v", val, a))
+			form.AddFieldError(ø, newValidationError(ø, CodeNotInSelection, val, Params{"selection": a}, "%#v not in %+v", val, a))
 		}
 	case Float:
 		a := ø.Selection.([]float32)
 		val := form.Floats[ø]
 		if !ø.hasFloat(a, val) {
-			form.AddFieldError(ø, fmt.Errorf("%#v not in %+v", val, a))
+			form.AddFieldError(ø, newValidationError(ø, CodeNotInSelection, val, Params{"selection": a}, "%#v not in %+v", val, a))
 		}
 	case String:
 		a := ø.Selection.([]string)
 		val := form.Strings[ø]
 		if !ø.hasString(a, val) {
-			form.AddFieldError(ø, fmt.Errorf("%#v not in %+v", val, a))
+			form.AddFieldError(ø, newValidationError(ø, CodeNotInSelection, val, Params{"selection": a}, "%#v not in %+v", val, a))
 		}
 	}
 }
@@ -0,0 +1,246 @@
+package goform
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	h "github.com/metakeule/goh4"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// UploadedFile is the parsed value of a File or FileArray Field
+type UploadedFile struct {
+	Filename    string // the filename as sent by the client, without any directory
+	Size        int64
+	ContentType string // detected from the content, not the header sent by the client
+	Location    string // where the file has been stored by the FileStorage of the form, if any
+	header      *multipart.FileHeader
+}
+
+// Open returns a reader for the content of the uploaded file.
+// The caller is responsible for closing it.
+func (ø *UploadedFile) Open() (multipart.File, error) {
+	return ø.header.Open()
+}
+
+func newUploadedFile(fh *multipart.FileHeader) (ø *UploadedFile, err error) {
+	ø = &UploadedFile{
+		Filename: filepath.Base(fh.Filename),
+		Size:     fh.Size,
+		header:   fh,
+	}
+	file, err := fh.Open()
+	if err != nil {
+		return
+	}
+	defer file.Close()
+	buf := make([]byte, 512)
+	n, err := io.ReadFull(file, buf)
+	if err == io.ErrUnexpectedEOF || err == io.EOF {
+		err = nil
+	}
+	if err != nil {
+		return
+	}
+	ø.ContentType = http.DetectContentType(buf[:n])
+	return
+}
+
+// FileStorage stores uploaded files somewhere and returns their location
+type FileStorage interface {
+	Store(file *UploadedFile, content io.Reader) (location string, err error)
+}
+
+// FileRemover may be implemented by a FileStorage to remove stored files again,
+// if storing another file or the Action of the form fails
+type FileRemover interface {
+	Remove(location string) error
+}
+
+// DiskStorage is a FileStorage that writes uploaded files with random names into Dir
+type DiskStorage struct {
+	Dir  string
+	Perm os.FileMode // permissions of the stored files, 0 means 0644
+}
+
+func (ø *DiskStorage) Store(file *UploadedFile, content io.Reader) (location string, err error) {
+	rnd := make([]byte, 16)
+	if _, err = rand.Read(rnd); err != nil {
+		return
+	}
+	location = filepath.Join(ø.Dir, hex.EncodeToString(rnd)+strings.ToLower(filepath.Ext(file.Filename)))
+	perm := ø.Perm
+	if perm == 0 {
+		perm = 0644
+	}
+	out, err := os.OpenFile(location, os.O_WRONLY|os.O_CREATE|os.O_EXCL, perm)
+	if err != nil {
+		return
+	}
+	_, err = io.Copy(out, content)
+	if cerr := out.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(location)
+		location = ""
+	}
+	return
+}
+
+func (ø *DiskStorage) Remove(location string) error {
+	return os.Remove(location)
+}
+
+// MaxFileSize sets the maximal size in bytes of each file uploaded for the given File or FileArray Field
+func MaxFileSize(field *Field, size int64) *Field {
+	field.MaxFileSize = size
+	return field
+}
+
+// MimeTypes restricts the allowed content types of the files uploaded for the given File or FileArray Field.
+// A type may end with "/*" to allow all subtypes. The accept attribute of the input is set accordingly.
+func MimeTypes(field *Field, types ...string) *Field {
+	field.MimeTypes = types
+	if fs := field.Element.Fields(); len(fs) > 0 {
+		fs[0].Add(h.Attr("accept", strings.Join(types, ",")))
+	}
+	return field
+}
+
+func (ø *FormHandler) parseFiles(files map[string][]*multipart.FileHeader) {
+	for _, k := range ø.orderedFields() {
+		if k.Type == Collection {
+			for _, entry := range ø.Collections[k] {
+				entry.parseFiles(files)
+			}
+			continue
+		}
+		fhs := files[k.Name]
+		if len(fhs) == 0 {
+			continue
+		}
+
+		switch ø.Types[k] {
+		case File:
+			f, err := newUploadedFile(fhs[0])
+			if err != nil {
+				ø.AddFieldError(k, newValidationError(k, CodeUnreadableFile, fhs[0].Filename, Params{"error": err.Error()}, "%#v could not be read: %s", fhs[0].Filename, err))
+				continue
+			}
+			ø.Files[k] = f
+		case FileArray:
+			m := []*UploadedFile{}
+			for _, fh := range fhs {
+				f, err := newUploadedFile(fh)
+				if err != nil {
+					ø.AddFieldError(k, newValidationError(k, CodeUnreadableFile, fh.Filename, Params{"error": err.Error()}, "%#v could not be read: %s", fh.Filename, err))
+					continue
+				}
+				m = append(m, f)
+			}
+			if len(m) == 0 {
+				continue
+			}
+			ø.FileArrays[k] = m
+		default:
+			continue
+		}
+		ø.FilledFields = append(ø.FilledFields, k.Name)
+	}
+}
+
+// stores all uploaded files with the FileStorage of the form, if any.
+// If a file can't be stored, the files stored before are removed.
+func (ø *FormHandler) storeFiles() (err error) {
+	if ø.FileStorage == nil {
+		return
+	}
+	for _, f := range ø.uploadedFiles() {
+		err = ø.storeFile(f)
+		if err != nil {
+			ø.removeFiles()
+			return
+		}
+	}
+	return
+}
+
+// removes the stored files, if the FileStorage is a FileRemover
+func (ø *FormHandler) removeFiles() {
+	remover, ok := ø.FileStorage.(FileRemover)
+	if !ok {
+		return
+	}
+	for _, f := range ø.uploadedFiles() {
+		if f.Location != "" && remover.Remove(f.Location) == nil {
+			f.Location = ""
+		}
+	}
+}
+
+// the uploaded files of the form, including those of Collection entries
+func (ø *FormHandler) uploadedFiles() (all []*UploadedFile) {
+	for _, f := range ø.Files {
+		all = append(all, f)
+	}
+	for _, fs := range ø.FileArrays {
+		all = append(all, fs...)
+	}
+	for _, entries := range ø.Collections {
+		for _, entry := range entries {
+			all = append(all, entry.uploadedFiles()...)
+		}
+	}
+	return
+}
+
+func (ø *FormHandler) storeFile(f *UploadedFile) (err error) {
+	content, err := f.Open()
+	if err != nil {
+		return
+	}
+	defer content.Close()
+	f.Location, err = ø.FileStorage.Store(f, content)
+	return
+}
+
+func (ø *Field) checkUploads(form *FormHandler) {
+	switch ø.Type {
+	case File:
+		if f := form.Files[ø]; f != nil {
+			ø.checkUpload(form, f)
+		}
+	case FileArray:
+		for _, f := range form.FileArrays[ø] {
+			ø.checkUpload(form, f)
+		}
+	}
+}
+
+func (ø *Field) checkUpload(form *FormHandler, f *UploadedFile) {
+	if ø.MaxFileSize > 0 && f.Size > ø.MaxFileSize {
+		form.AddFieldError(ø, newValidationError(ø, CodeFileTooLarge, f.Filename, Params{"size": f.Size, "max_size": ø.MaxFileSize}, "%#v is too large (%d > %d bytes)", f.Filename, f.Size, ø.MaxFileSize))
+	}
+	if len(ø.MimeTypes) > 0 && !ø.hasMimeType(f.ContentType) {
+		form.AddFieldError(ø, newValidationError(ø, CodeInvalidMimeType, f.Filename, Params{"content_type": f.ContentType, "mime_types": ø.MimeTypes}, "%#v has content type %#v, not in %+v", f.Filename, f.ContentType, ø.MimeTypes))
+	}
+}
+
+func (ø *Field) hasMimeType(contentType string) (has bool) {
+	has = false
+	mediaType := strings.TrimSpace(strings.SplitN(contentType, ";", 2)[0])
+	for _, allowed := range ø.MimeTypes {
+		if allowed == mediaType {
+			return true
+		}
+		if strings.HasSuffix(allowed, "/*") && strings.HasPrefix(mediaType, strings.TrimSuffix(allowed, "*")) {
+			return true
+		}
+	}
+	return
+}
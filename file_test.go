@@ -0,0 +1,125 @@
+package goform
+
+import (
+	"bytes"
+	"errors"
+	. "github.com/metakeule/goh4/tag"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+)
+
+func newUploadRequest(field string, contents ...string) *http.Request {
+	var buf bytes.Buffer
+	w := multipart.NewWriter(&buf)
+	for i, c := range contents {
+		part, _ := w.CreateFormFile(field, string(rune('a'+i))+".txt")
+		part.Write([]byte(c))
+	}
+	w.Close()
+	r := httptest.NewRequest("POST", "/", &buf)
+	r.Header.Set("Content-Type", w.FormDataContentType())
+	return r
+}
+
+func TestParseRequestFile(t *testing.T) {
+	f := NewForm(Required("Doc", File, INPUT()))
+	f.FileStorage = &DiskStorage{Dir: t.TempDir()}
+
+	if e := f.ParseRequest(newUploadRequest("Doc", "hello world")); e != nil {
+		t.Fatalf("unexpected error: %s", e)
+	}
+
+	doc := f.Get("Doc").(*UploadedFile)
+
+	if doc.Filename != "a.txt" {
+		err(t, "incorrect filename", doc.Filename, "a.txt")
+	}
+
+	if doc.Size != 11 {
+		err(t, "incorrect size", doc.Size, 11)
+	}
+
+	if doc.ContentType != "text/plain; charset=utf-8" {
+		err(t, "incorrect content type", doc.ContentType, "text/plain; charset=utf-8")
+	}
+
+	stored, e := os.ReadFile(doc.Location)
+	if e != nil || string(stored) != "hello world" {
+		err(t, "incorrect stored file", string(stored), "hello world")
+	}
+}
+
+func TestParseRequestFileArrayRules(t *testing.T) {
+	f := NewForm(MimeTypes(MaxFileSize(Optional("Images", FileArray, INPUT()), 5), "image/*"))
+
+	if e := f.ParseRequest(newUploadRequest("Images", "tiny", "too large")); e == nil {
+		t.Fatalf("expected field errors")
+	}
+
+	fld := f.Field("Images")
+
+	if len(f.FileArrays[fld]) != 2 {
+		err(t, "incorrect number of files", len(f.FileArrays[fld]), 2)
+	}
+
+	// both are no images, the second one is too large
+	if len(f.FieldErrors[fld]) != 3 {
+		err(t, "incorrect number of errors", f.FieldErrors[fld], 3)
+	}
+}
+
+func TestRequiredFileArrayUnreadable(t *testing.T) {
+	f := NewForm(Required("Docs", FileArray, INPUT()))
+	fld := f.Field("Docs")
+
+	// the uploads have neither content nor a temporary file
+	f.parseFiles(map[string][]*multipart.FileHeader{"Docs": {{Filename: "a.txt"}, {Filename: "b.txt"}}})
+	f.Validate()
+
+	if f.FileArrays[fld] != nil || f.IsFilledField(fld) {
+		err(t, "unreadable uploads should not fill the Field", f.FileArrays[fld], nil)
+	}
+
+	if len(f.FieldErrors[fld]) != 3 {
+		err(t, "the uploads and the required Field should be invalid", f.FieldErrors[fld], 3)
+	}
+}
+
+func TestStoreFilesOnlyForAction(t *testing.T) {
+	dir := t.TempDir()
+	f := NewForm(Required("Doc", File, INPUT()))
+	f.FileStorage = &DiskStorage{Dir: dir}
+	f.BeforeAction = func(f *FormHandler) { f.AddValidationError(errors.New("rejected")) }
+	f.Action = func(*FormHandler) error { return nil }
+
+	if e := f.ParseRequest(newUploadRequest("Doc", "hello world")); e == nil {
+		t.Fatalf("expected the error of BeforeAction")
+	}
+
+	if entries, _ := os.ReadDir(dir); len(entries) != 0 {
+		err(t, "no file should be stored if BeforeAction fails", len(entries), 0)
+	}
+
+	f = NewForm(Required("Doc", File, INPUT()))
+	f.FileStorage = &DiskStorage{Dir: dir}
+	var location string
+	f.Action = func(f *FormHandler) error {
+		location = f.Get("Doc").(*UploadedFile).Location
+		return errors.New("failed")
+	}
+
+	if e := f.ParseRequest(newUploadRequest("Doc", "hello world")); e == nil {
+		t.Fatalf("expected the error of the Action")
+	}
+
+	if _, e := os.Stat(location); location == "" || !os.IsNotExist(e) {
+		err(t, "the stored file should be removed if the Action fails", location, "")
+	}
+
+	if f.Get("Doc").(*UploadedFile).Location != "" {
+		err(t, "the location of the removed file should be reset", f.Get("Doc").(*UploadedFile).Location, "")
+	}
+}
@@ -2,11 +2,11 @@ package goform
 
 import (
 	"encoding/json"
-	"fmt"
 	h "github.com/metakeule/goh4"
 	. "github.com/metakeule/goh4/tag"
 	"strconv"
 	"strings"
+	"time"
 )
 
 type FormHandler struct {
@@ -22,9 +22,16 @@ type FormHandler struct {
 	JsonStructs   map[*Field]interface{}
 	JsonsOriginal map[*Field]string
 	Fills         map[*Field]Filler
+	Files         map[*Field]*UploadedFile
+	FileArrays    map[*Field][]*UploadedFile
+	Times         map[*Field]time.Time // for Date, Time and DateTime Fields
+	Durations     map[*Field]time.Duration
+	Collections   map[*Field][]*FormHandler // the parsed entries of Collection Fields
+	FileStorage   FileStorage               // if set, uploaded files are stored before the Action is run
 	Types         map[*Field]Type
 	Fields        map[string]*Field
 	FilledFields  []string
+	Submitted     map[*Field][]string // the raw submitted values of the parsed Fields
 	Order         []h.Stringer
 	required      []*Field
 	Validation    func(*FormHandler)       // may call AddFieldError and AddValidationError
@@ -41,14 +48,32 @@ type FormHandler struct {
 
 	FieldErrors             map[*Field][]error // to collect all the errors of the Fields
 	GeneralValidationErrors []error            // to collect all validation errors that are a result of different Field values
+
+	Locale  string  // the locale for error messages, labels, numbers and dates, e.g. "de", empty means untranslated
+	Catalog Catalog // the translations, nil means the bundled Messages
+	Theme   Theme   // the css classes, nil means PlainTheme, see SetTheme
+
+	requestLocale string // the locale of the Accept-Language header, only used for messages and labels
+
+	CSRF CSRFStore // if set, ParseRequest only accepts requests with a valid CSRF token, see AddCSRFToken
+
+	MaxMemory   int64 // bytes of a multipart body kept in memory by ParseRequest, the rest goes to temporary files (0 means DefaultMaxMemory)
+	MaxBodySize int64 // maximal size in bytes of a request body accepted by ParseRequest (0 means no limit)
+
+	errorSummary *h.Element // the summary of the GeneralValidationErrors added by RenderSubmission
 }
 
 func (ø *FormHandler) resetElement() {
 	ø.Element = FORM(ATTR("method", "POST", "enctype", "multipart/form-data"))
+	ø.errorSummary = nil
 	for _, s := range ø.Order {
-		if f, ok := s.(*Field); ok {
-			ø.Element.Add(f.Element)
-		} else {
+		switch v := s.(type) {
+		case *Field:
+			ø.Element.Add(v.Element)
+		case *Group:
+			v.resetElement()
+			ø.Element.Add(v.Element)
+		default:
 			ø.Element.Add(s)
 		}
 	}
@@ -68,16 +93,19 @@ func (ø *FormHandler) Reset() {
 	ø.JsonsOriginal = map[*Field]string{}
 	ø.GeneralValidationErrors = []error{}
 	ø.Fills = map[*Field]Filler{}
+	ø.Files = map[*Field]*UploadedFile{}
+	ø.FileArrays = map[*Field][]*UploadedFile{}
+	ø.Times = map[*Field]time.Time{}
+	ø.Durations = map[*Field]time.Duration{}
+	ø.Collections = map[*Field][]*FormHandler{}
+	ø.Submitted = map[*Field][]string{}
 }
 
 func (ø *FormHandler) AddTitle(el *h.Element) { ø.AddAtPosition(0, el) }
 
 func (ø *FormHandler) AddSubmitButton(value string) (el *h.Element) {
-	el = INPUT(
-		h.Class("submit"),
-		h.Class("btn"),
-		h.Class("btn-primary"),
-		h.Attr("type", "submit", "value", value))
+	el = INPUT(h.Attr("type", "submit", "value", value))
+	el.AddClass(ø.theme().Button()...)
 	ø.Element.Add(el)
 	return
 }
@@ -135,19 +163,32 @@ func (ø *FormHandler) IsNil(field *Field) (is bool) {
 		if ø.Fills[field] == nil {
 			return true
 		}
+	case File:
+		if ø.Files[field] == nil {
+			return true
+		}
+	case FileArray:
+		if ø.FileArrays[field] == nil {
+			return true
+		}
+	case Date, Time, DateTime:
+		if ø.Times[field].IsZero() {
+			return true
+		}
+	case Duration:
+		if ø.Durations[field] == 0 {
+			return true
+		}
+	case Collection:
+		if len(ø.Collections[field]) == 0 {
+			return true
+		}
 	}
 	return
 }
 
 func (ø *FormHandler) removeFieldFromOrder(f *Field) {
-	newOrder := []h.Stringer{}
-	for _, s := range ø.Order {
-		if fld, ok := s.(*Field); ok && fld == f {
-			continue
-		}
-		newOrder = append(newOrder, s)
-	}
-	ø.Order = newOrder
+	ø.Order = removeFromOrder(ø.Order, f)
 }
 
 func (ø *FormHandler) RemoveFieldFromRequired(f *Field) {
@@ -191,6 +232,16 @@ func (ø *FormHandler) RemoveField(fld string) {
 	case Fill:
 		delete(ø.Fills, field)
 		delete(ø.JsonsOriginal, field)
+	case File:
+		delete(ø.Files, field)
+	case FileArray:
+		delete(ø.FileArrays, field)
+	case Date, Time, DateTime:
+		delete(ø.Times, field)
+	case Duration:
+		delete(ø.Durations, field)
+	case Collection:
+		delete(ø.Collections, field)
 	}
 	ø.removeFieldFromOrder(field)
 	if field.Required {
@@ -204,12 +255,12 @@ func (ø *FormHandler) RemoveField(fld string) {
 func (ø *FormHandler) Validate() {
 	for _, field := range ø.required {
 		if ø.IsNil(field) {
-			ø.AddFieldError(field, fmt.Errorf("required"))
+			ø.AddFieldError(field, newValidationError(field, CodeRequired, nil, nil, "required"))
 		}
 	}
 
 	for _, field := range ø.Fields {
-		field.CheckAllowed(ø)
+		ø.checkField(field)
 	}
 
 	if ø.Validation != nil {
@@ -224,283 +275,235 @@ func (ø *FormHandler) Validate() {
 
 }
 
+// runs the checks of the Field itself, without the required check
+func (ø *FormHandler) checkField(field *Field) {
+	field.CheckAllowed(ø)
+	field.checkUploads(ø)
+	field.checkTimeRange(ø)
+	field.runValidators(ø)
+	if field.Type == Collection {
+		ø.checkEntries(field)
+	}
+}
+
+// ParseFormValues parses values like url.Values, where each array element is a separate value
 func (ø *FormHandler) ParseFormValues(vals map[string][]string) (err error) {
-	ø.FilledFields = []string{}
-	//ø.FieldErrors = map[*Field][]error{}
-	//ø.GeneralValidationErrors = []error{}
+	return ø.ParseSource(URLValues(vals))
+}
+
+// Parse parses a flat map of values, where the elements of arrays are separated by commas
+// (or semicolons for numbers in locales with a decimal comma, see FlatValues)
+func (ø *FormHandler) Parse(vals map[string]string) (err error) {
+	return ø.ParseSource(FlatValues{Map: vals, Locale: ø.Locale})
+}
+
+// ParseSource parses the values of the given source into the typed maps and
+// runs the hooks, the validation and the action
+func (ø *FormHandler) ParseSource(src ValueSource) (err error) {
+	ø.beforeParsing()
+	ø.parseSource(src)
+	return ø.afterParsing()
+}
+
+func (ø *FormHandler) beforeParsing() {
+	ø.resetParsed()
 	if ø.BeforeParsing != nil {
 		ø.BeforeParsing(ø)
 	}
+}
 
-	for kk, v := range vals {
-		if len(v) == 0 {
+// forgets which Fields were filled by the last parsing
+func (ø *FormHandler) resetParsed() {
+	ø.FilledFields = []string{}
+	ø.Submitted = map[*Field][]string{}
+	//ø.FieldErrors = map[*Field][]error{}
+	//ø.GeneralValidationErrors = []error{}
+}
+
+// parses the values of the source into the typed maps, without running any hooks.
+// Empty strings are the same as "Null": Fields whose values are all empty are not filled.
+func (ø *FormHandler) parseSource(src ValueSource) {
+	for _, k := range ø.orderedFields() {
+		if k.Type == File || k.Type == FileArray {
+			// files are only taken from multipart bodies, see ParseRequest
 			continue
 		}
-		if ø.Fields[kk] == nil {
+		if k.Type == Collection {
+			ø.parseCollection(k, src)
 			continue
 		}
+		raw, ok := src.Values(k)
+		if !ok {
+			continue
+		}
+		var isNative []bool
+		if ns, ok := src.(nativeSource); ok {
+			isNative = ns.native(k)
+		}
+		v, native := []string{}, []bool{}
+		for i, str := range raw {
+			if str != "" {
+				v = append(v, str)
+				native = append(native, i < len(isNative) && isNative[i])
+			}
+		}
+		if len(v) == 0 {
+			continue
+		}
+		ø.parseValues(k, v, native)
+		ø.Submitted[k] = v
+		ø.FilledFields = append(ø.FilledFields, k.Name)
+	}
+}
 
-		k := ø.Fields[kk]
-
-		switch ø.Types[k] {
-		case Int:
-			i, err := strconv.ParseInt(v[0], 0, 32)
+// parses the non empty values v of the Field k into the typed maps.
+// native tells for each value if it is a json number or boolean, see JSONValues.
+func (ø *FormHandler) parseValues(k *Field, v []string, native []bool) {
+	switch ø.Types[k] {
+	case Int:
+		i, err := ø.parseInt(k, strings.TrimSpace(v[0]), native[0])
+		if err != nil {
+			ø.AddFieldError(k, newValidationError(k, CodeNotInt, v[0], nil, "%#v is no int", v[0]))
+		}
+		ø.Ints[k] = int(i)
+	case String:
+		if native[0] {
+			ø.AddFieldError(k, newValidationError(k, CodeNotString, v[0], nil, "%#v is no string", v[0]))
+		}
+		ø.Strings[k] = v[0]
+	case Bool:
+		if v[0] == "on" {
+			ø.Bools[k] = true
+		} else {
+			b, err := strconv.ParseBool(strings.TrimSpace(v[0]))
 			if err != nil {
-				ø.AddFieldError(k, fmt.Errorf("%#v is no int", v[0]))
+				ø.AddFieldError(k, newValidationError(k, CodeNotBool, v[0], nil, "%#v is no bool", v[0]))
 			}
-			ø.Ints[k] = int(i)
-		case String:
-			ø.Strings[k] = v[0]
-		case Bool:
-			if v[0] == "on" {
-				ø.Bools[k] = true
-			} else {
-				b, err := strconv.ParseBool(v[0])
-				if err != nil {
-					ø.AddFieldError(k, fmt.Errorf("%#v is no bool", v[0]))
-				}
-				ø.Bools[k] = b
-			}
-
-		case Float:
-			fl, err := strconv.ParseFloat(v[0], 32)
+			ø.Bools[k] = b
+		}
+	case Float:
+		fl, err := ø.parseFloat(k, strings.TrimSpace(v[0]), native[0])
+		if err != nil {
+			ø.AddFieldError(k, newValidationError(k, CodeNotFloat, v[0], nil, "%#v is no float", v[0]))
+		}
+		ø.Floats[k] = float32(fl)
+	case IntArray:
+		m := []int{}
+		for j, str := range v {
+			i, err := ø.parseInt(k, strings.TrimSpace(str), native[j])
 			if err != nil {
-				ø.AddFieldError(k, fmt.Errorf("%#v is no float", v[0]))
+				ø.AddFieldError(k, newValidationError(k, CodeNotInt, str, nil, "%#v is no int", str))
 			}
-			ø.Floats[k] = float32(fl)
-		case IntArray:
-			m := []int{}
-			for _, str := range v {
-				trimmed := strings.Trim(str, " ")
-				i, err := strconv.ParseInt(trimmed, 0, 32)
-				if err != nil {
-					ø.AddFieldError(k, fmt.Errorf("%#v is no int", str))
-				}
-				m = append(m, int(i))
+			m = append(m, int(i))
+		}
+		ø.IntArrays[k] = m
+	case FloatArray:
+		m := []float32{}
+		for j, str := range v {
+			fl, err := ø.parseFloat(k, strings.TrimSpace(str), native[j])
+			if err != nil {
+				ø.AddFieldError(k, newValidationError(k, CodeNotFloat, str, nil, "%#v is no float", str))
 			}
-			ø.IntArrays[k] = m
-		case FloatArray:
-			m := []float32{}
-			for _, str := range v {
-				trimmed := strings.Trim(str, " ")
-				i, err := strconv.ParseFloat(trimmed, 32)
-				if err != nil {
-					ø.AddFieldError(k, fmt.Errorf("%#v is no float", str))
-				}
-				m = append(m, float32(i))
+			m = append(m, float32(fl))
+		}
+		ø.FloatArrays[k] = m
+	case StringArray:
+		m := []string{}
+		for j, str := range v {
+			if native[j] {
+				ø.AddFieldError(k, newValidationError(k, CodeNotString, str, nil, "%#v is no string", str))
 			}
-			ø.FloatArrays[k] = m
-
-		case StringArray:
-			m := []string{}
-			for _, str := range v {
+			if !k.multipleEmails() {
 				m = append(m, str)
+				continue
 			}
-			ø.StringArrays[k] = m
-		case Struct:
-			ø.JsonsOriginal[k] = v[0]
-			ø.JsonStructs[k] = k.Constructor()
-			i := ø.JsonStructs[k]
-
-			dec := json.NewDecoder(strings.NewReader(v[0]))
-			err = dec.Decode(i)
-			if err != nil {
-				ø.AddFieldError(k, fmt.Errorf("%#v could not be parsed: %s", v[0], err))
-			}
-		case Map:
-			ø.JsonsOriginal[k] = v[0]
-			var ii map[string]interface{}
-			err = json.Unmarshal([]byte(v[0]), &ii)
-			ø.JsonMaps[k] = ii
-			if err != nil {
-				ø.AddFieldError(k, fmt.Errorf("%#v could not be parsed: %s", v[0], err))
-			}
-		case Fill:
-			ø.JsonsOriginal[k] = v[0]
-			var ii map[string]interface{}
-			err = json.Unmarshal([]byte(v[0]), &ii)
-			for kk, vv := range ii {
-				if fl_v, ok := vv.(float64); ok {
-					if float64(int(fl_v)) == fl_v {
-						ii[kk] = int(fl_v)
-					}
+			// the browser submits the emails as one value, separated by commas
+			for _, email := range strings.Split(str, ",") {
+				if email = strings.TrimSpace(email); email != "" {
+					m = append(m, email)
 				}
 			}
-			if err != nil {
-				ø.AddFieldError(k, fmt.Errorf("%#v could not be parsed: %s", v[0], err))
+		}
+		ø.StringArrays[k] = m
+	case Struct:
+		ø.JsonsOriginal[k] = v[0]
+		ø.JsonStructs[k] = k.Constructor()
+		i := ø.JsonStructs[k]
+
+		dec := json.NewDecoder(strings.NewReader(v[0]))
+		err := dec.Decode(i)
+		if err != nil {
+			ø.AddFieldError(k, newValidationError(k, CodeInvalidJSON, v[0], Params{"error": err.Error()}, "%#v could not be parsed: %s", v[0], err))
+		}
+	case Map:
+		ø.JsonsOriginal[k] = v[0]
+		var ii map[string]interface{}
+		err := json.Unmarshal([]byte(v[0]), &ii)
+		ø.JsonMaps[k] = ii
+		if err != nil {
+			ø.AddFieldError(k, newValidationError(k, CodeInvalidJSON, v[0], Params{"error": err.Error()}, "%#v could not be parsed: %s", v[0], err))
+		}
+	case Fill:
+		ø.JsonsOriginal[k] = v[0]
+		var ii map[string]interface{}
+		err := json.Unmarshal([]byte(v[0]), &ii)
+		for kk, vv := range ii {
+			if fl_v, ok := vv.(float64); ok {
+				if float64(int(fl_v)) == fl_v {
+					ii[kk] = int(fl_v)
+				}
 			}
-			ø.Fills[k].Fill(ii)
 		}
-		ø.FilledFields = append(ø.FilledFields, k.Name)
-	}
-	if ø.AfterParsing != nil {
-		ø.AfterParsing(ø)
-	}
-
-	if ø.BeforeValidation != nil {
-		ø.BeforeValidation(ø)
+		if err != nil {
+			ø.AddFieldError(k, newValidationError(k, CodeInvalidJSON, v[0], Params{"error": err.Error()}, "%#v could not be parsed: %s", v[0], err))
+		}
+		ø.Fills[k].Fill(ii)
+	case Date, Time, DateTime, Duration:
+		ø.parseTime(k, strings.TrimSpace(v[0]))
 	}
+}
 
-	ø.Validate()
-
-	if ø.AfterValidation != nil {
-		ø.AfterValidation(ø)
-	}
+// runs everything that follows the parsing: the AfterParsing hook, the validation and the action
+func (ø *FormHandler) afterParsing() (err error) {
+	ø.runValidation()
 
 	if len(ø.FieldErrors) == 0 && len(ø.GeneralValidationErrors) == 0 {
-		if ø.Action != nil {
-			if ø.BeforeAction != nil {
-				ø.BeforeAction(ø)
-			}
+		// without an Action the stored files are left to the caller
+		if ø.Action == nil {
+			return ø.storeFiles()
+		}
 
-			if len(ø.FieldErrors) > 0 || len(ø.GeneralValidationErrors) > 0 {
-				err = fmt.Errorf("Field errors or general validation errors")
-				return
-			}
+		if ø.BeforeAction != nil {
+			ø.BeforeAction(ø)
+		}
 
-			err = ø.Action(ø)
-			if err == nil && ø.AfterAction != nil {
-				ø.AfterAction(ø)
+		if err = ø.formErrors(); err != nil {
+			return
+		}
 
-				if len(ø.FieldErrors) > 0 || len(ø.GeneralValidationErrors) > 0 {
-					err = fmt.Errorf("Field errors or general validation errors")
-				}
-			}
+		if err = ø.storeFiles(); err != nil {
+			return
 		}
-		return
-	}
 
-	if len(ø.FieldErrors) > 0 && len(ø.GeneralValidationErrors) > 0 {
-		err = fmt.Errorf("Field errors and general validation errors")
-		return
-	}
+		err = ø.Action(ø)
+		if err != nil {
+			ø.removeFiles()
+			return
+		}
 
-	if len(ø.FieldErrors) > 0 {
-		err = fmt.Errorf("Field errors")
-		return
-	}
+		if ø.AfterAction != nil {
+			ø.AfterAction(ø)
 
-	if len(ø.GeneralValidationErrors) > 0 {
-		err = fmt.Errorf("general validation errors")
+			err = ø.formErrors()
+		}
+		return
 	}
 
-	return
+	return ø.formErrors()
 }
 
-func (ø *FormHandler) Parse(vals map[string]string) (err error) {
-	ø.FilledFields = []string{}
-	//ø.FieldErrors = map[*Field][]error{}
-	//ø.GeneralValidationErrors = []error{}
-	if ø.BeforeParsing != nil {
-		ø.BeforeParsing(ø)
-	}
-
-	for kk, v := range vals {
-		// empty strings are same as  "Null"
-		if v == "" {
-			continue
-		}
-		if ø.Fields[kk] == nil {
-			continue
-		}
-
-		k := ø.Fields[kk]
-
-		switch ø.Types[k] {
-		case Int:
-			i, err := strconv.ParseInt(v, 0, 32)
-			if err != nil {
-				ø.AddFieldError(k, fmt.Errorf("%#v is no int", v))
-			}
-			ø.Ints[k] = int(i)
-		case String:
-			ø.Strings[k] = v
-		case Bool:
-			if v == "on" {
-				ø.Bools[k] = true
-			} else {
-				b, err := strconv.ParseBool(v)
-				if err != nil {
-					ø.AddFieldError(k, fmt.Errorf("%#v is no bool", v))
-				}
-				ø.Bools[k] = b
-			}
-
-		case Float:
-			fl, err := strconv.ParseFloat(v, 32)
-			if err != nil {
-				ø.AddFieldError(k, fmt.Errorf("%#v is no float", v))
-			}
-			ø.Floats[k] = float32(fl)
-		case IntArray:
-			m := []int{}
-			a := strings.Split(v, ",")
-			for _, str := range a {
-				trimmed := strings.Trim(str, " ")
-				i, err := strconv.ParseInt(trimmed, 0, 32)
-				if err != nil {
-					ø.AddFieldError(k, fmt.Errorf("%#v is no int", str))
-				}
-				m = append(m, int(i))
-			}
-			ø.IntArrays[k] = m
-		case FloatArray:
-			m := []float32{}
-			a := strings.Split(v, ",")
-			for _, str := range a {
-				trimmed := strings.Trim(str, " ")
-				i, err := strconv.ParseFloat(trimmed, 32)
-				if err != nil {
-					ø.AddFieldError(k, fmt.Errorf("%#v is no float", str))
-				}
-				m = append(m, float32(i))
-			}
-			ø.FloatArrays[k] = m
-
-		case StringArray:
-			m := []string{}
-			a := strings.Split(v, ",")
-			for _, str := range a {
-				i := strings.Trim(str, " ")
-				m = append(m, i)
-			}
-			ø.StringArrays[k] = m
-		case Struct:
-			ø.JsonsOriginal[k] = v
-			ø.JsonStructs[k] = k.Constructor()
-			i := ø.JsonStructs[k]
-
-			dec := json.NewDecoder(strings.NewReader(v))
-			err = dec.Decode(i)
-			if err != nil {
-				ø.AddFieldError(k, fmt.Errorf("%#v could not be parsed: %s", v, err))
-			}
-		case Map:
-			ø.JsonsOriginal[k] = v
-			var ii map[string]interface{}
-			err = json.Unmarshal([]byte(v), &ii)
-			ø.JsonMaps[k] = ii
-			if err != nil {
-				ø.AddFieldError(k, fmt.Errorf("%#v could not be parsed: %s", v, err))
-			}
-		case Fill:
-			ø.JsonsOriginal[k] = v
-			var ii map[string]interface{}
-			err = json.Unmarshal([]byte(v), &ii)
-			for kk, vv := range ii {
-				if fl_v, ok := vv.(float64); ok {
-					if float64(int(fl_v)) == fl_v {
-						ii[kk] = int(fl_v)
-					}
-				}
-			}
-			if err != nil {
-				ø.AddFieldError(k, fmt.Errorf("%#v could not be parsed: %s", v, err))
-			}
-			ø.Fills[k].Fill(ii)
-		}
-		ø.FilledFields = append(ø.FilledFields, k.Name)
-	}
+// runs the AfterParsing hook and the validation with its hooks
+func (ø *FormHandler) runValidation() {
 	if ø.AfterParsing != nil {
 		ø.AfterParsing(ø)
 	}
@@ -514,45 +517,6 @@ func (ø *FormHandler) Parse(vals map[string]string) (err error) {
 	if ø.AfterValidation != nil {
 		ø.AfterValidation(ø)
 	}
-
-	if len(ø.FieldErrors) == 0 && len(ø.GeneralValidationErrors) == 0 {
-		if ø.Action != nil {
-			if ø.BeforeAction != nil {
-				ø.BeforeAction(ø)
-			}
-
-			if len(ø.FieldErrors) > 0 || len(ø.GeneralValidationErrors) > 0 {
-				err = fmt.Errorf("Field errors or general validation errors")
-				return
-			}
-
-			err = ø.Action(ø)
-			if err == nil && ø.AfterAction != nil {
-				ø.AfterAction(ø)
-
-				if len(ø.FieldErrors) > 0 || len(ø.GeneralValidationErrors) > 0 {
-					err = fmt.Errorf("Field errors or general validation errors")
-				}
-			}
-		}
-		return
-	}
-
-	if len(ø.FieldErrors) > 0 && len(ø.GeneralValidationErrors) > 0 {
-		err = fmt.Errorf("Field errors and general validation errors")
-		return
-	}
-
-	if len(ø.FieldErrors) > 0 {
-		err = fmt.Errorf("Field errors")
-		return
-	}
-
-	if len(ø.GeneralValidationErrors) > 0 {
-		err = fmt.Errorf("general validation errors")
-	}
-
-	return
 }
 
 func (ø *FormHandler) IsFilledField(f *Field) (is bool) {
@@ -581,16 +545,22 @@ func (ø *FormHandler) AddHtml(s h.Stringer) {
 }
 
 func (ø *FormHandler) AddField(f *Field) {
+	ø.registerField(f)
+	ø.Order = append(ø.Order, f)
+	ø.Element.Add(f.Element)
+}
+
+// makes the Field known to the form, without adding it to the Order
+func (ø *FormHandler) registerField(f *Field) {
 	if ø.HasFieldDefinition(f.Name) {
 		panic("Field " + f.Name + " already defined")
 	}
-	ø.Order = append(ø.Order, f)
+	f.setTheme(ø.theme())
 	ø.Types[f] = f.Type
 	ø.Fields[f.Name] = f
 	if f.Required {
 		ø.required = append(ø.required, f)
 	}
-	ø.Element.Add(f.Element)
 }
 
 func (ø *FormHandler) Field(fld string) (f *Field) {
@@ -609,8 +579,12 @@ func (ø *FormHandler) Get(field string) interface{} {
 	if ø.Fields[field] == nil {
 		panic("field " + field + " does not exist")
 	}
+	return ø.get(ø.Fields[field])
+}
 
-	k := ø.Fields[field]
+// the value of the Field, the Fields of Collection entries are named by their path
+// and can't be looked up by name
+func (ø *FormHandler) get(k *Field) interface{} {
 	if !ø.IsFilledField(k) {
 		return nil
 	}
@@ -635,6 +609,20 @@ func (ø *FormHandler) Get(field string) interface{} {
 		return ø.JsonMaps[k]
 	case Fill:
 		return ø.Fills[k]
+	case File:
+		return ø.Files[k]
+	case FileArray:
+		return ø.FileArrays[k]
+	case Date, Time, DateTime:
+		return ø.Times[k]
+	case Duration:
+		return ø.Durations[k]
+	case Collection:
+		m := []map[string]interface{}{}
+		for _, entry := range ø.Collections[k] {
+			m = append(m, entry.Map())
+		}
+		return m
 	}
-	panic("can't get field " + field + ": unknown type")
+	panic("can't get field " + k.Name + ": unknown type")
 }
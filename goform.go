@@ -21,6 +21,7 @@ func NewForm(objects ...interface{}) (f *FormHandler) {
 		Element:  FORM(ATTR("method", "POST", "enctype", "multipart/form-data")),
 	}
 
+	var theme Theme
 	for _, obj := range objects {
 		switch v := obj.(type) {
 		case []*Field:
@@ -29,10 +30,19 @@ func NewForm(objects ...interface{}) (f *FormHandler) {
 			}
 		case *Field:
 			f.AddField(v)
+		case *Group:
+			f.AddGroup(v)
+		case Theme:
+			theme = v
+		case CSRFStore:
+			f.CSRF = v
 		default:
 			f.AddHtml(v.(h.Stringer))
 		}
 	}
+	if theme != nil {
+		f.SetTheme(theme)
+	}
 	f.Reset()
 	return
 }
@@ -83,6 +93,9 @@ func Required(name string, t Typer, html ...interface{}) (ø *Field) {
 	if c, ok := t.(Constructor); ok {
 		ø.Constructor = c
 	}
+	if d, ok := t.(*FormDefinition); ok {
+		ø.Definition = d
+	}
 	ø.setFieldInfos()
 	return
 }
@@ -94,6 +107,9 @@ func Optional(name string, t Typer, html ...interface{}) (ø *Field) {
 	if c, ok := t.(Constructor); ok {
 		ø.Constructor = c
 	}
+	if d, ok := t.(*FormDefinition); ok {
+		ø.Definition = d
+	}
 	ø.setFieldInfos()
 	return
 }
@@ -0,0 +1,180 @@
+package goform
+
+import (
+	h "github.com/metakeule/goh4"
+	. "github.com/metakeule/goh4/tag"
+)
+
+// Group is a section of a form with a legend. It contains Fields, html and
+// further Groups in Order and is rendered as fieldset, or as details element
+// if it is Collapsible, with the id "group-" followed by the Name.
+type Group struct {
+	*h.Element
+	Name        string
+	Legend      string
+	Order       []h.Stringer
+	Collapsible bool
+	Open        bool // only for Collapsible Groups, if the Group is expanded
+}
+
+// Fieldset returns a Group with the given name and legend that contains the
+// given Fields, Groups and html, see NewForm
+func Fieldset(name string, legend string, objects ...interface{}) (ø *Group) {
+	ø = &Group{Name: name, Legend: legend, Order: []h.Stringer{}}
+	for _, obj := range objects {
+		switch v := obj.(type) {
+		case []*Field:
+			for _, field := range v {
+				ø.Order = append(ø.Order, field)
+			}
+		default:
+			ø.Order = append(ø.Order, v.(h.Stringer))
+		}
+	}
+	ø.resetElement()
+	return
+}
+
+// Collapsible makes the Group collapsible, open sets if it is expanded initially.
+// RenderSubmission expands collapsed Groups that contain Fields with errors.
+func Collapsible(group *Group, open bool) *Group {
+	group.Collapsible = true
+	group.Open = open
+	group.resetElement()
+	return group
+}
+
+func (ø *Group) resetElement() {
+	if ø.Collapsible {
+		ø.Element = DETAILS(h.Id(ø.elementId()), SUMMARY(h.Text(ø.Legend)))
+		if ø.Open {
+			ø.Element.Add(h.Attr("open", "open"))
+		}
+	} else {
+		ø.Element = FIELDSET(h.Id(ø.elementId()), LEGEND(h.Text(ø.Legend)))
+	}
+	for _, s := range ø.Order {
+		switch v := s.(type) {
+		case *Field:
+			ø.Element.Add(v.Element)
+		case *Group:
+			v.resetElement()
+			ø.Element.Add(v.Element)
+		default:
+			ø.Element.Add(s)
+		}
+	}
+}
+
+// the id of the element of the Group, it is prefixed so that it doesn't clash
+// with the ids of Fields of the same name
+func (ø *Group) elementId() string {
+	return "group-" + ø.Name
+}
+
+// expands a collapsed Group
+func (ø *Group) open() {
+	if ø.Collapsible && !ø.Open {
+		ø.Open = true
+		ø.Element.Add(h.Attr("open", "open"))
+	}
+}
+
+// expands the collapsed Groups of the order that contain Fields with errors
+// and returns if the order contains such Fields
+func (ø *FormHandler) openGroups(order []h.Stringer) (hasErrors bool) {
+	for _, s := range order {
+		switch v := s.(type) {
+		case *Field:
+			if len(ø.FieldErrors[v]) > 0 {
+				hasErrors = true
+			}
+		case *Group:
+			if ø.openGroups(v.Order) {
+				v.open()
+				hasErrors = true
+			}
+		}
+	}
+	return
+}
+
+// the Fields of the order, including those of Groups
+func fieldsOf(order []h.Stringer) (fields []*Field) {
+	for _, s := range order {
+		switch v := s.(type) {
+		case *Field:
+			fields = append(fields, v)
+		case *Group:
+			fields = append(fields, fieldsOf(v.Order)...)
+		}
+	}
+	return
+}
+
+// removes the Field from the order, including those of Groups
+func removeFromOrder(order []h.Stringer, f *Field) []h.Stringer {
+	newOrder := []h.Stringer{}
+	for _, s := range order {
+		switch v := s.(type) {
+		case *Field:
+			if v == f {
+				continue
+			}
+		case *Group:
+			v.Order = removeFromOrder(v.Order, f)
+		}
+		newOrder = append(newOrder, s)
+	}
+	return newOrder
+}
+
+// AddGroup adds the Group and its Fields to the form
+func (ø *FormHandler) AddGroup(g *Group) {
+	for _, f := range fieldsOf(g.Order) {
+		ø.registerField(f)
+	}
+	ø.Order = append(ø.Order, g)
+	ø.Element.Add(g.Element)
+}
+
+// the Fields of the form in Order, including those of Groups
+func (ø *FormHandler) orderedFields() []*Field {
+	return fieldsOf(ø.Order)
+}
+
+// ErrorSection holds the FieldErrors of the Fields that are directly contained
+// in a Group, or that are not part of any Group if the Group is nil
+type ErrorSection struct {
+	Group       *Group
+	FieldErrors map[*Field][]error
+}
+
+// ErrorSections returns the FieldErrors grouped by sections: first the Fields
+// outside of Groups, then the Groups in Order (nested Groups follow their parent).
+// Sections without errors are left out.
+func (ø *FormHandler) ErrorSections() (sections []ErrorSection) {
+	var walk func(g *Group, order []h.Stringer)
+	walk = func(g *Group, order []h.Stringer) {
+		section := ErrorSection{Group: g, FieldErrors: map[*Field][]error{}}
+		groups := []*Group{}
+		for _, s := range order {
+			switch v := s.(type) {
+			case *Field:
+				if errs := ø.FieldErrors[v]; len(errs) > 0 {
+					section.FieldErrors[v] = errs
+				}
+			case *Group:
+				groups = append(groups, v)
+			}
+		}
+		if len(section.FieldErrors) > 0 {
+			sections = append(sections, section)
+		}
+		for _, group := range groups {
+			walk(group, group.Order)
+		}
+	}
+	walk(nil, ø.Order)
+	return
+}
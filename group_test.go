@@ -0,0 +1,90 @@
+package goform
+
+import (
+	h "github.com/metakeule/goh4"
+	. "github.com/metakeule/goh4/tag"
+	"testing"
+)
+
+func newGroupForm() *FormHandler {
+	return NewForm(
+		Required("Name", String, INPUT()),
+		Fieldset("address", "Address",
+			Required("City", String, INPUT()),
+			P(h.Text("only in germany")),
+			Collapsible(Fieldset("extra", "More",
+				Validators(Optional("Zip", String, INPUT()), MinLength(5)),
+			), false),
+		),
+	)
+}
+
+func TestFieldset(t *testing.T) {
+	f := newGroupForm()
+
+	if f.Field("Zip") == nil || len(f.required) != 2 {
+		err(t, "fields of groups should be part of the form", f.Fields, "Name, City, Zip")
+	}
+
+	fs := f.Any(h.Id("group-address"))
+	if fs == nil || fs.Tag() != "fieldset" || fs.Any(h.Tag("legend")) == nil || fs.Any(h.Id("City")) == nil {
+		err(t, "incorrect fieldset", f.String(), `fieldset with legend and City`)
+	}
+
+	details := f.Any(h.Id("group-extra"))
+	if details == nil || details.Tag() != "details" || details.Attribute("open") != "" {
+		err(t, "incorrect collapsible group", f.String(), "closed details")
+	}
+
+	f.RemoveField("City")
+
+	if f.Any(h.Id("City")) != nil || f.Any(h.Id("Zip")) == nil || f.Any(h.Tag("p")) == nil {
+		err(t, "RemoveField should keep the groups", f.String(), "groups without City")
+	}
+
+	if len(f.Describe().Items[1].Group.Items[1].Group.Items) != 1 {
+		err(t, "incorrect description of nested group", f.Describe().Items[1], "group with Zip")
+	}
+}
+
+func TestErrorSections(t *testing.T) {
+	f := newGroupForm()
+	f.Parse(map[string]string{"Zip": "123"})
+
+	sections := f.ErrorSections()
+
+	if len(sections) != 3 {
+		err(t, "incorrect number of sections", len(sections), 3)
+		return
+	}
+
+	if sections[0].Group != nil || len(sections[0].FieldErrors[f.Field("Name")]) != 1 {
+		err(t, "incorrect first section", sections[0], "Name outside of groups")
+	}
+
+	if sections[1].Group.Name != "address" || len(sections[1].FieldErrors[f.Field("City")]) != 1 {
+		err(t, "incorrect second section", sections[1], "City in address")
+	}
+
+	if sections[2].Group.Name != "extra" || len(sections[2].FieldErrors[f.Field("Zip")]) != 1 {
+		err(t, "incorrect third section", sections[2], "Zip in extra")
+	}
+
+	f.RenderSubmission()
+
+	if f.Any(h.Id("group-extra")).Attribute("open") != "open" {
+		err(t, "groups with errors should be expanded", f.Any(h.Id("group-extra")).String(), "open")
+	}
+}
+
+func TestGroupIdDiffersFromField(t *testing.T) {
+	f := NewForm(Fieldset("Address", "Address", Required("Address", String, INPUT())))
+
+	if el := f.Any(h.Id("Address")); el == nil || el.Tag() != "input" {
+		err(t, "the id of the field should be unique", f.String(), `<input id="Address" ...`)
+	}
+
+	if el := f.Any(h.Id("group-Address")); el == nil || el.Tag() != "fieldset" {
+		err(t, "the id of the group should be prefixed", f.String(), `<fieldset id="group-Address">`)
+	}
+}
@@ -0,0 +1,240 @@
+package goform
+
+import (
+	"fmt"
+	h "github.com/metakeule/goh4"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Catalog provides translated texts by locale and key. The keys are the
+// codes of ValidationErrors (see the Code constants) and "label." followed
+// by a Field name for the label of the Field.
+// Texts for errors may contain the placeholders {field}, {value} and
+// {<param>} for each parameter of the ValidationError, e.g. {min}.
+type Catalog interface {
+	Locales() []string
+	Translate(locale string, key string) (text string, ok bool)
+}
+
+// MapCatalog is a Catalog that maps locales to keys to texts
+type MapCatalog map[string]map[string]string
+
+func (ø MapCatalog) Locales() (locales []string) {
+	locales = []string{}
+	for l := range ø {
+		locales = append(locales, l)
+	}
+	sort.Strings(locales)
+	return
+}
+
+func (ø MapCatalog) Translate(locale string, key string) (text string, ok bool) {
+	text, ok = ø[locale][key]
+	return
+}
+
+// Messages is the bundled Catalog with english ("en") and german ("de") error messages.
+// It is used if a FormHandler has a Locale but no Catalog.
+var Messages = MapCatalog{
+	"en": {
+		CodeRequired:         "required",
+		CodeNotInt:           `"{value}" is no whole number`,
+		CodeNotFloat:         `"{value}" is no number`,
+		CodeNotBool:          `"{value}" is neither true nor false`,
+		CodeNotString:        `"{value}" is no text`,
+		CodeNotTime:          `"{value}" does not match the format {layout}`,
+		CodeNotDuration:      `"{value}" is no duration`,
+		CodeInvalidJSON:      "invalid json: {error}",
+		CodeNotInSelection:   `"{value}" is not one of {selection}`,
+		CodeTooEarly:         "must not be before {min}",
+		CodeTooLate:          "must not be after {max}",
+		CodeDurationTooShort: "must not be shorter than {min}",
+		CodeDurationTooLong:  "must not be longer than {max}",
+		CodeUnreadableFile:   `"{value}" could not be read`,
+		CodeFileTooLarge:     `"{value}" is larger than {max_size} bytes`,
+		CodeInvalidMimeType:  `"{value}" has the type {content_type} which is not one of {mime_types}`,
+		CodeMin:              "must be at least {min}",
+		CodeMax:              "must be at most {max}",
+		CodeTooShort:         "must have at least {min_length} characters",
+		CodeTooLong:          "must have at most {max_length} characters",
+		CodePattern:          "has an invalid format",
+		CodeTooFewItems:      "needs at least {min_items} entries",
+		CodeTooManyItems:     "allows at most {max_items} entries",
+		CodeEmail:            "is no valid email address",
+		CodeURL:              "is no valid url",
+		CodeUUID:             "is no valid uuid",
+		CodeCSRF:             "the form has expired or was not sent from this site, please submit it again",
+	},
+	"de": {
+		CodeRequired:         "Pflichtfeld",
+		CodeNotInt:           `"{value}" ist keine ganze Zahl`,
+		CodeNotFloat:         `"{value}" ist keine Zahl`,
+		CodeNotBool:          `"{value}" ist weder wahr noch falsch`,
+		CodeNotString:        `"{value}" ist kein Text`,
+		CodeNotTime:          `"{value}" entspricht nicht dem Format {layout}`,
+		CodeNotDuration:      `"{value}" ist keine Dauer`,
+		CodeInvalidJSON:      "ungültiges JSON: {error}",
+		CodeNotInSelection:   `"{value}" ist nicht in {selection}`,
+		CodeTooEarly:         "darf nicht vor {min} liegen",
+		CodeTooLate:          "darf nicht nach {max} liegen",
+		CodeDurationTooShort: "darf nicht kürzer als {min} sein",
+		CodeDurationTooLong:  "darf nicht länger als {max} sein",
+		CodeUnreadableFile:   `"{value}" konnte nicht gelesen werden`,
+		CodeFileTooLarge:     `"{value}" ist größer als {max_size} Bytes`,
+		CodeInvalidMimeType:  `"{value}" hat den Typ {content_type}, erlaubt sind {mime_types}`,
+		CodeMin:              "muss mindestens {min} sein",
+		CodeMax:              "darf höchstens {max} sein",
+		CodeTooShort:         "muss mindestens {min_length} Zeichen haben",
+		CodeTooLong:          "darf höchstens {max_length} Zeichen haben",
+		CodePattern:          "hat ein ungültiges Format",
+		CodeTooFewItems:      "braucht mindestens {min_items} Einträge",
+		CodeTooManyItems:     "erlaubt höchstens {max_items} Einträge",
+		CodeEmail:            "ist keine gültige E-Mail-Adresse",
+		CodeURL:              "ist keine gültige URL",
+		CodeUUID:             "ist keine gültige UUID",
+		CodeCSRF:             "das Formular ist abgelaufen oder wurde nicht von dieser Seite gesendet, bitte erneut absenden",
+	},
+}
+
+func (ø *FormHandler) catalog() Catalog {
+	if ø.Catalog != nil {
+		return ø.Catalog
+	}
+	return Messages
+}
+
+// ErrorMessage returns the text of the given error in the Locale of the form or,
+// without it, in the locale of the Accept-Language header (see ParseRequest).
+// ValidationErrors are translated with the Catalog of the form, other errors
+// and errors without a translation keep their own message.
+func (ø *FormHandler) ErrorMessage(err error) string {
+	ve, ok := err.(*ValidationError)
+	if !ok || ø.messageLocale() == "" {
+		return err.Error()
+	}
+	text, ok := ø.catalog().Translate(ø.messageLocale(), ve.Code)
+	if !ok {
+		return err.Error()
+	}
+	replacements := []string{"{field}", ve.Field, "{value}", fmt.Sprint(ve.Value)}
+	for k, v := range ve.Params {
+		replacements = append(replacements, "{"+k+"}", fmt.Sprint(v))
+	}
+	return strings.NewReplacer(replacements...).Replace(text)
+}
+
+// TranslateLabels sets the texts of the labels of all Fields that have a
+// translation in the Catalog for the locale of the messages (key "label." + Field name).
+// The text of a label is replaced, the elements inside of it (e.g. the input) are kept.
+// If the label starts with a SPAN, like the labels of NewFormFromStruct, the text
+// of the SPAN is replaced instead.
+func (ø *FormHandler) TranslateLabels() {
+	if ø.messageLocale() == "" {
+		return
+	}
+	for name, field := range ø.Fields {
+		text, ok := ø.catalog().Translate(ø.messageLocale(), "label."+name)
+		if !ok {
+			continue
+		}
+		if label := field.Element.Any(h.Tag("label")); label != nil {
+			direct := children(label)
+			if len(direct) > 0 && direct[0].Tag() == "span" && len(direct[0].Fields()) == 0 &&
+				strings.HasPrefix(label.InnerHtml(), "<span") {
+				direct[0].SetContent(h.Text(text))
+				continue
+			}
+			content := []interface{}{h.Text(text)}
+			for _, el := range direct {
+				content = append(content, el)
+			}
+			label.SetContent(content...)
+		}
+	}
+}
+
+// matches every element
+type anyElement struct{}
+
+func (anyElement) Matches(*h.Element) bool { return true }
+
+// returns the elements that are direct children of el
+func children(el *h.Element) (direct []*h.Element) {
+	nested := map[*h.Element]bool{}
+	all := el.All(anyElement{})
+	for _, d := range all {
+		for _, n := range d.All(anyElement{}) {
+			nested[n] = true
+		}
+	}
+	for _, d := range all {
+		if !nested[d] {
+			direct = append(direct, d)
+		}
+	}
+	return
+}
+
+// returns the locale for messages and labels: the Locale of the form or, without
+// it, the locale of the Accept-Language header of the parsed request
+func (ø *FormHandler) messageLocale() string {
+	if ø.Locale != "" {
+		return ø.Locale
+	}
+	return ø.requestLocale
+}
+
+// SetLocaleFromRequest sets the Locale of the form to the best match of the
+// Accept-Language header of the request among the locales of the Catalog.
+// The Locale is not changed if there is no match. Since the Locale is also used
+// for parsing numbers and dates, the client then decides how they are parsed.
+func (ø *FormHandler) SetLocaleFromRequest(r *http.Request) {
+	if l := NegotiateLocale(r.Header.Get("Accept-Language"), ø.catalog().Locales()); l != "" {
+		ø.Locale = l
+	}
+}
+
+// NegotiateLocale returns the locale of available that matches the given
+// Accept-Language header best or "" if none matches. A language range
+// matches a locale if they are equal or the range is more specific
+// (e.g. "de-AT" matches "de").
+func NegotiateLocale(acceptLanguage string, available []string) string {
+	type lang struct {
+		tag string
+		q   float64
+	}
+	langs := []lang{}
+	for _, part := range strings.Split(acceptLanguage, ",") {
+		fields := strings.Split(strings.TrimSpace(part), ";")
+		l := lang{tag: strings.ToLower(strings.TrimSpace(fields[0])), q: 1}
+		for _, f := range fields[1:] {
+			f = strings.TrimSpace(f)
+			if strings.HasPrefix(f, "q=") {
+				if q, err := strconv.ParseFloat(f[2:], 64); err == nil {
+					l.q = q
+				}
+			}
+		}
+		if l.tag != "" && l.q > 0 {
+			langs = append(langs, l)
+		}
+	}
+	sort.SliceStable(langs, func(i, j int) bool { return langs[i].q > langs[j].q })
+
+	for _, l := range langs {
+		for _, a := range available {
+			if strings.EqualFold(a, l.tag) {
+				return a
+			}
+		}
+		for _, a := range available {
+			if strings.HasPrefix(l.tag, strings.ToLower(a)+"-") {
+				return a
+			}
+		}
+	}
+	return ""
+}
@@ -0,0 +1,117 @@
+package goform
+
+import (
+	h "github.com/metakeule/goh4"
+	. "github.com/metakeule/goh4/tag"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestNegotiateLocale(t *testing.T) {
+	available := []string{"de", "en"}
+	for header, locale := range map[string]string{
+		"de-DE,de;q=0.9,en;q=0.8": "de",
+		"fr, en-GB;q=0.5":         "en",
+		"en;q=0.2, de;q=0.7":      "de",
+		"fr":                      "",
+		"":                        "",
+	} {
+		if is := NegotiateLocale(header, available); is != locale {
+			err(t, "incorrect locale for "+header, is, locale)
+		}
+	}
+}
+
+func TestTranslatedErrors(t *testing.T) {
+	f := NewForm(
+		Required("Name", String, LABEL(INPUT())),
+		Validators(Optional("Age", Int, LABEL(INPUT())), Min(18)),
+	)
+	f.Catalog = MapCatalog{
+		"de": {
+			CodeRequired: Messages["de"][CodeRequired],
+			CodeMin:      Messages["de"][CodeMin],
+			"label.Name": "Ihr Name",
+		},
+	}
+
+	r := httptest.NewRequest("POST", "/", strings.NewReader(url.Values{"Age": {"12"}}.Encode()))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	r.Header.Set("Accept-Language", "de-DE,de;q=0.9")
+	_ = f.ParseRequest(r)
+
+	if f.Locale != "" {
+		err(t, "the header should not set the locale for parsing", f.Locale, "")
+	}
+
+	if msg := f.ErrorMessage(f.FieldErrors[f.Field("Age")][0]); msg != "muss mindestens 18 sein" {
+		err(t, "incorrect message", msg, "muss mindestens 18 sein")
+	}
+
+	f.TranslateLabels()
+	f.RenderSubmission()
+
+	html := f.String()
+	for _, s := range []string{"Ihr Name", "Pflichtfeld"} {
+		if !strings.Contains(html, s) {
+			err(t, "missing in html", html, s)
+		}
+	}
+}
+
+func TestAcceptLanguageKeepsNumbers(t *testing.T) {
+	f := NewForm(
+		Required("Name", String, INPUT()),
+		Optional("Price", Float, INPUT()),
+	)
+
+	r := httptest.NewRequest("POST", "/", strings.NewReader(url.Values{"Price": {"1.5"}}.Encode()))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	r.Header.Set("Accept-Language", "de")
+	_ = f.ParseRequest(r)
+
+	if price := f.Floats[f.Field("Price")]; price != 1.5 {
+		err(t, "the header should not change how numbers are parsed", price, float32(1.5))
+	}
+
+	if msg := f.ErrorMessage(f.FieldErrors[f.Field("Name")][0]); msg != Messages["de"][CodeRequired] {
+		err(t, "the messages should be translated to the locale of the header", msg, Messages["de"][CodeRequired])
+	}
+}
+
+func TestTranslateLabelsReplacesText(t *testing.T) {
+	f := NewForm(
+		Required("Name", String, LABEL(h.Text("Name"), INPUT())),
+		Optional("Newsletter", Bool, LABEL(INPUT(h.Attr("type", "checkbox")), SPAN("!"))),
+	)
+	f.Locale = "de"
+	f.Catalog = MapCatalog{"de": {"label.Name": "Ihr Name", "label.Newsletter": "Newsletter"}}
+
+	f.TranslateLabels()
+	f.TranslateLabels()
+
+	label := f.Field("Name").Element.Any(h.Tag("label"))
+	if html := label.InnerHtml(); strings.Count(html, "Ihr Name") != 1 || !strings.HasPrefix(html, "Ihr Name<input") {
+		err(t, "the text of the label should be replaced once", html, "Ihr Name<input ...")
+	}
+
+	label = f.Field("Newsletter").Element.Any(h.Tag("label"))
+	if label.Any(h.Tag("input")) == nil || label.Any(h.Tag("span")) == nil || !strings.HasPrefix(label.InnerHtml(), "Newsletter<input") {
+		err(t, "the elements of the label should be kept", label.InnerHtml(), "Newsletter<input ...><span>!</span>")
+	}
+}
+
+func TestTranslateLabelsOfStructForm(t *testing.T) {
+	f := NewFormFromStruct(&structFormPerson{})
+	f.Locale = "de"
+	f.Catalog = MapCatalog{"de": {"label.name": "Ihr Name"}}
+
+	f.TranslateLabels()
+
+	label := f.Field("name").Element.Any(h.Tag("label"))
+	if html := label.InnerHtml(); strings.Contains(html, "Your Name") || !strings.HasPrefix(html, "<span>Ihr Name</span><input") {
+		err(t, "the text of the span should be replaced", html, "<span>Ihr Name</span><input ...>")
+	}
+}
@@ -0,0 +1,26 @@
+package goform
+
+import (
+	"encoding/json"
+	"io"
+)
+
+// ParseJSON parses a json object whose top level keys are Field names and runs
+// the same pipeline as Parse (see JSONValues for the conversion of the values).
+// An error is returned for an invalid json document, otherwise the errors are
+// reported like by Parse: as *FormErrors keyed by Field name.
+func (ø *FormHandler) ParseJSON(r io.Reader) (err error) {
+	vals, err := decodeJSON(r)
+	if err != nil {
+		return
+	}
+	return ø.ParseSource(vals)
+}
+
+// decodes a json object, keeping numbers as they are written
+func decodeJSON(r io.Reader) (vals JSONValues, err error) {
+	dec := json.NewDecoder(r)
+	dec.UseNumber()
+	err = dec.Decode(&vals)
+	return
+}
@@ -0,0 +1,125 @@
+package goform
+
+import (
+	"errors"
+	. "github.com/metakeule/goh4/tag"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestParseJSON(t *testing.T) {
+	newForm := func() *FormHandler {
+		return NewForm(
+			Required("Name", String, INPUT()),
+			Optional("Age", Int, INPUT()),
+			Optional("Admin", Bool, INPUT()),
+			Optional("Scores", FloatArray, INPUT()),
+			Optional("Address", Constructor(func() interface{} { return &Address{} }), TEXTAREA()),
+			Optional("Details", Map, TEXTAREA()),
+		)
+	}
+
+	f := newForm()
+	e := f.ParseJSON(strings.NewReader(`{
+		"Name": "Donald",
+		"Age": 144,
+		"Admin": true,
+		"Scores": [1.5, 2],
+		"Address": {"City": "Entenhausen", "Zip": 12345},
+		"Details": {"nephews": ["Huey", "Dewey", "Louie"]},
+		"Unknown": 1
+	}`))
+	if e != nil {
+		t.Fatalf("unexpected error: %s", e)
+	}
+
+	if f.Get("Age") != 144 || f.Get("Admin") != true || f.FloatArrays[f.Field("Scores")][0] != 1.5 {
+		err(t, "incorrect values", f.Map(), "Age 144, Admin true, Scores [1.5 2]")
+	}
+
+	if addr := f.Get("Address").(*Address); addr.City != "Entenhausen" || addr.Zip != 12345 {
+		err(t, "incorrect Address", addr, "Entenhausen 12345")
+	}
+
+	if nephews := f.JsonMaps[f.Field("Details")]["nephews"].([]interface{}); len(nephews) != 3 {
+		err(t, "incorrect Details", nephews, 3)
+	}
+
+	f = newForm()
+	e = f.ParseJSON(strings.NewReader(`{"Age": 1.5, "Admin": [true]}`))
+	var fe *FormErrors
+	if !errors.As(e, &fe) || len(fe.FieldErrors["Name"]) != 1 || len(fe.FieldErrors["Age"]) != 1 || len(fe.FieldErrors["Admin"]) != 1 {
+		err(t, "expected errors for Name, Age and Admin", e, "Field errors")
+	}
+
+	if e = newForm().ParseJSON(strings.NewReader(`{"Name": `)); e == nil || errors.Is(e, ErrInvalid) {
+		err(t, "expected a json syntax error", e, "unexpected EOF")
+	}
+}
+
+func TestParseRequestJSON(t *testing.T) {
+	f := NewForm(Required("Name", String, INPUT()))
+	r := httptest.NewRequest("POST", "/", strings.NewReader(`{"Name": "Donald"}`))
+	r.Header.Set("Content-Type", "application/json; charset=utf-8")
+
+	if e := f.ParseRequest(r); e != nil || f.Get("Name") != "Donald" {
+		err(t, "incorrect Name", f.Get("Name"), "Donald")
+	}
+}
+
+func TestParseJSONLocale(t *testing.T) {
+	f := NewForm(
+		Optional("Ratio", Float, INPUT()),
+		Optional("Count", Int, INPUT()),
+		Optional("Scores", FloatArray, INPUT()),
+		Optional("Price", Float, INPUT()),
+	)
+	f.Locale = "de"
+
+	e := f.ParseJSON(strings.NewReader(`{"Ratio": 1.125, "Count": 1000, "Scores": [0.5, "1,5"], "Price": "1.234,5"}`))
+	if e != nil {
+		t.Fatalf("unexpected error: %s", e)
+	}
+
+	if f.Floats[f.Field("Ratio")] != 1.125 || f.Ints[f.Field("Count")] != 1000 {
+		err(t, "json numbers should not be parsed with the locale", f.Map(), "Ratio 1.125, Count 1000")
+	}
+
+	scores := f.FloatArrays[f.Field("Scores")]
+	if len(scores) != 2 || scores[0] != 0.5 || scores[1] != 1.5 {
+		err(t, "strings should still be parsed with the locale", scores, []float32{0.5, 1.5})
+	}
+
+	if f.Floats[f.Field("Price")] != 1234.5 {
+		err(t, "incorrect Price", f.Floats[f.Field("Price")], 1234.5)
+	}
+
+	f = NewForm(Optional("Count", Int, INPUT()))
+	f.Locale = "de"
+	var fe *FormErrors
+	e = f.ParseJSON(strings.NewReader(`{"Count": 1.5}`))
+	if !errors.As(e, &fe) || fe.FieldErrors["Count"][0].(*ValidationError).Code != CodeNotInt {
+		err(t, "1.5 should be no int", e, CodeNotInt)
+	}
+}
+
+func TestParseJSONNotString(t *testing.T) {
+	f := NewForm(
+		Optional("Name", String, INPUT()),
+		Optional("Tags", StringArray, INPUT()),
+		Optional("Zip", String, INPUT()),
+	)
+
+	var fe *FormErrors
+	e := f.ParseJSON(strings.NewReader(`{"Name": 144, "Tags": ["a", true], "Zip": "12345"}`))
+	if !errors.As(e, &fe) || len(fe.FieldErrors) != 2 {
+		t.Fatalf("expected errors for Name and Tags, got %v", e)
+	}
+
+	for _, name := range []string{"Name", "Tags"} {
+		if ve, ok := fe.FieldErrors[name][0].(*ValidationError); !ok || ve.Code != CodeNotString {
+			err(t, "incorrect error for "+name, fe.FieldErrors[name], CodeNotString)
+		}
+	}
+}
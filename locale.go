@@ -0,0 +1,206 @@
+package goform
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// NumberFormat describes how numbers are written in a locale
+type NumberFormat struct {
+	Decimal  string // the decimal separator
+	Grouping string // the separator between groups of thousands
+}
+
+// NumberFormats are the number formats by locale. Int, Float, IntArray and
+// FloatArray Fields are parsed and formatted with the number format of their
+// locale, unless their input is of type number (browsers always submit those
+// in the canonical format).
+var NumberFormats = map[string]NumberFormat{
+	"en": {Decimal: ".", Grouping: ","},
+	"de": {Decimal: ",", Grouping: "."},
+}
+
+// LocaleLayouts are the layouts for Date, Time and DateTime Fields by locale.
+// They are used instead of the DefaultLayouts if the Field has no Layout and
+// its input is no native date or time input (browsers always submit those
+// in the DefaultLayouts).
+var LocaleLayouts = map[string]map[Type]string{
+	"de": {
+		Date:     "02.01.2006",
+		Time:     "15:04",
+		DateTime: "02.01.2006 15:04",
+	},
+}
+
+// the locale of the Field, which may overwrite the locale of the form
+func (ø *Field) locale(form *FormHandler) string {
+	if ø.Locale != "" {
+		return ø.Locale
+	}
+	return form.Locale
+}
+
+// looks up the entry for the locale or its language (e.g. "de" for "de-AT")
+func lookupLocale(locale string, fn func(string) bool) bool {
+	if locale == "" {
+		return false
+	}
+	if fn(locale) {
+		return true
+	}
+	if i := strings.IndexAny(locale, "-_"); i > 0 {
+		return fn(locale[:i])
+	}
+	return false
+}
+
+// returns true if the inner form field of the Field is an input whose values
+// are submitted by browsers in a locale independent format
+func (ø *Field) hasNativeInput() bool {
+	fs := ø.Element.Fields()
+	if len(fs) == 0 || fs[0].Tag() != "input" {
+		return false
+	}
+	switch fs[0].Attribute("type") {
+	case "number", "range", "date", "time", "datetime-local":
+		return true
+	}
+	return false
+}
+
+func (ø *Field) numberFormat(locale string) (nf NumberFormat, ok bool) {
+	if ø.hasNativeInput() {
+		return
+	}
+	ok = lookupLocale(locale, func(l string) (found bool) {
+		nf, found = NumberFormats[l]
+		return
+	})
+	return
+}
+
+// the separator of the values of an array Field in one string, ";" for numbers
+// with a decimal comma, otherwise ","
+func (ø *Field) arraySeparator(locale string) string {
+	if ø.Type == IntArray || ø.Type == FloatArray {
+		if nf, ok := ø.numberFormat(locale); ok && nf.Decimal == "," {
+			return ";"
+		}
+	}
+	return ","
+}
+
+func (ø *Field) localeLayout(locale string) (layout string, ok bool) {
+	if ø.Layout != "" || ø.hasNativeInput() {
+		return
+	}
+	ok = lookupLocale(locale, func(l string) (found bool) {
+		layout, found = LocaleLayouts[l][ø.Type]
+		return
+	})
+	return
+}
+
+// parses an int in the number format of the locale of the Field, or in the
+// format of strconv if it is native, e.g. a json number
+func (ø *FormHandler) parseInt(k *Field, s string, native bool) (int64, error) {
+	if native {
+		return strconv.ParseInt(s, 10, 32)
+	}
+	if nf, ok := k.numberFormat(k.locale(ø)); ok {
+		if canonical, ok := nf.canonical(s, false); ok {
+			s = canonical
+		}
+	}
+	return strconv.ParseInt(s, 0, 32)
+}
+
+// parses a float like parseInt
+func (ø *FormHandler) parseFloat(k *Field, s string, native bool) (float64, error) {
+	if native {
+		return strconv.ParseFloat(s, 32)
+	}
+	if nf, ok := k.numberFormat(k.locale(ø)); ok {
+		if canonical, ok := nf.canonical(s, true); ok {
+			s = canonical
+		}
+	}
+	return strconv.ParseFloat(s, 32)
+}
+
+// converts a number written in the NumberFormat to the format of strconv.
+// Grouping separators must separate groups of three digits, otherwise
+// the number is not in the NumberFormat and ok is false.
+func (ø NumberFormat) canonical(s string, allowDecimal bool) (c string, ok bool) {
+	s = strings.TrimSpace(s)
+	sign := ""
+	if strings.HasPrefix(s, "-") || strings.HasPrefix(s, "+") {
+		sign, s = s[:1], s[1:]
+	}
+	intPart, frac := s, ""
+	if i := strings.Index(s, ø.Decimal); i >= 0 {
+		if !allowDecimal {
+			return
+		}
+		intPart, frac = s[:i], s[i+len(ø.Decimal):]
+		if frac == "" || !isDigits(frac) {
+			return
+		}
+	}
+	groups := []string{intPart}
+	if ø.Grouping != "" {
+		groups = strings.Split(intPart, ø.Grouping)
+	}
+	for i, g := range groups {
+		if !isDigits(g) || (i == 0 && len(groups) > 1 && len(g) > 3) || (i > 0 && len(g) != 3) {
+			return
+		}
+	}
+	c = sign + strings.Join(groups, "")
+	if frac != "" {
+		c += "." + frac
+	}
+	return c, true
+}
+
+func isDigits(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// formats a float for the Field in the given locale, without grouping
+func (ø *Field) formatFloat(f float64, bitSize int, locale string) string {
+	s := strconv.FormatFloat(f, 'f', -1, bitSize)
+	if nf, ok := ø.numberFormat(locale); ok {
+		s = strings.Replace(s, ".", nf.Decimal, 1)
+	}
+	return s
+}
+
+// parses a value of a Date, Time or DateTime Field, trying the layout of the locale first
+func (ø *FormHandler) parseTimeValue(k *Field, v string) (t time.Time, layout string, err error) {
+	if l, ok := k.localeLayout(k.locale(ø)); ok {
+		if t, err = time.Parse(l, v); err == nil {
+			return t, l, nil
+		}
+	}
+	layout = k.TimeLayout()
+	t, err = time.Parse(layout, v)
+	return
+}
+
+// formats a value of a Date, Time or DateTime Field in the layout of the locale
+func (ø *Field) formatTime(t time.Time, locale string) string {
+	if l, ok := ø.localeLayout(locale); ok {
+		return t.Format(l)
+	}
+	return ø.FormatTime(t)
+}
@@ -0,0 +1,108 @@
+package goform
+
+import (
+	h "github.com/metakeule/goh4"
+	. "github.com/metakeule/goh4/tag"
+	"testing"
+	"time"
+)
+
+func TestLocaleNumbers(t *testing.T) {
+	f := NewForm(
+		Optional("Price", Float, INPUT()),
+		Optional("Count", Int, INPUT()),
+		Optional("Native", Float, INPUT(h.Attr("type", "number"))),
+		Optional("Prices", FloatArray, INPUT()),
+		Optional("Day", Date, INPUT(h.Attr("type", "text"))),
+	)
+	f.Locale = "de-DE"
+
+	_ = f.ParseFormValues(map[string][]string{
+		"Price":  {"1.234,5"},
+		"Count":  {"12.000"},
+		"Native": {"1.5"},
+		"Prices": {"0,25", "1000.5"},
+		"Day":    {"17.05.2013"},
+	})
+
+	if len(f.FieldErrors) != 0 {
+		t.Fatalf("unexpected errors: %v", f.FieldErrors)
+	}
+
+	for _, c := range []struct {
+		is, shouldbe interface{}
+	}{
+		{f.Floats[f.Field("Price")], float32(1234.5)},
+		{f.Ints[f.Field("Count")], 12000},
+		{f.Floats[f.Field("Native")], float32(1.5)},
+		{f.FloatArrays[f.Field("Prices")][0], float32(0.25)},
+		{f.FloatArrays[f.Field("Prices")][1], float32(1000.5)},
+		{f.Times[f.Field("Day")], time.Date(2013, 5, 17, 0, 0, 0, 0, time.UTC)},
+	} {
+		if c.is != c.shouldbe {
+			err(t, "incorrect value", c.is, c.shouldbe)
+		}
+	}
+
+	f.SetValues(map[string]interface{}{
+		"Price":  float32(1234.5),
+		"Native": 1.5,
+		"Day":    time.Date(2013, 5, 17, 0, 0, 0, 0, time.UTC),
+	})
+
+	for name, value := range map[string]string{"Price": "1234,5", "Native": "1.5", "Day": "17.05.2013"} {
+		if is := f.Field(name).Element.Fields()[0].Attribute("value"); is != value {
+			err(t, "incorrect rendered value of "+name, is, value)
+		}
+	}
+}
+
+func TestLocaleNumbersFlat(t *testing.T) {
+	f := NewForm(
+		Optional("Prices", FloatArray, INPUT()),
+		Optional("Single", FloatArray, INPUT()),
+		Optional("Tags", StringArray, INPUT()),
+	)
+	f.Locale = "de"
+
+	if e := f.Parse(map[string]string{"Prices": "0,25; 1,5", "Single": "0,25", "Tags": "a,b"}); e != nil {
+		t.Fatalf("unexpected error: %s", e)
+	}
+
+	prices := f.FloatArrays[f.Field("Prices")]
+	if len(prices) != 2 || prices[0] != 0.25 || prices[1] != 1.5 {
+		err(t, "incorrect Prices", prices, []float32{0.25, 1.5})
+	}
+
+	single := f.FloatArrays[f.Field("Single")]
+	if len(single) != 1 || single[0] != 0.25 {
+		err(t, "incorrect Single", single, []float32{0.25})
+	}
+
+	if len(f.StringArrays[f.Field("Tags")]) != 2 {
+		err(t, "strings should still be separated by commas", f.StringArrays[f.Field("Tags")], []string{"a", "b"})
+	}
+
+	f = NewForm(Optional("Prices", FloatArray, INPUT()))
+	if e := f.Parse(map[string]string{"Prices": "0.25,1.5"}); e != nil || len(f.FloatArrays[f.Field("Prices")]) != 2 {
+		err(t, "without locale the numbers should be separated by commas", f.FloatArrays[f.Field("Prices")], []float32{0.25, 1.5})
+	}
+}
+
+func TestLocaleNumbersRoundTrip(t *testing.T) {
+	for _, locale := range []string{"", "de"} {
+		f := NewForm(Optional("Prices", FloatArray, INPUT()))
+		f.Locale = locale
+		f.SetValues(map[string]interface{}{"Prices": []float32{1.5, 2.5}})
+		value := f.Any(h.Attr("name", "Prices")).Attribute("value")
+
+		if e := f.Parse(map[string]string{"Prices": value}); e != nil {
+			t.Fatalf("unexpected error for %#v: %s", value, e)
+		}
+
+		prices := f.FloatArrays[f.Field("Prices")]
+		if len(prices) != 2 || prices[0] != 1.5 || prices[1] != 2.5 {
+			err(t, "the rendered value "+value+" should be parsed back", prices, []float32{1.5, 2.5})
+		}
+	}
+}
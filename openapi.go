@@ -0,0 +1,135 @@
+package goform
+
+import (
+	"regexp"
+	"strings"
+)
+
+// OpenAPIRequestBody is an OpenAPI 3.1 request body object
+type OpenAPIRequestBody struct {
+	Description string                      `json:"description,omitempty"`
+	Required    bool                        `json:"required"`
+	Content     map[string]OpenAPIMediaType `json:"content"`
+}
+
+// OpenAPIMediaType is an OpenAPI 3.1 media type object
+type OpenAPIMediaType struct {
+	Schema   *Schema                    `json:"schema"`
+	Encoding map[string]OpenAPIEncoding `json:"encoding,omitempty"`
+}
+
+// OpenAPIEncoding is an OpenAPI 3.1 encoding object
+type OpenAPIEncoding struct {
+	ContentType string `json:"contentType,omitempty"`
+	Style       string `json:"style,omitempty"`
+	Explode     *bool  `json:"explode,omitempty"`
+}
+
+// OpenAPISchema returns the schema of the form for the components section of an
+// OpenAPI 3.1 document. It is the JSONSchema without the $schema keyword, since
+// OpenAPI 3.1 uses the draft 2020-12 dialect by default.
+func (ø *FormHandler) OpenAPISchema() *Schema {
+	return ø.objectSchema()
+}
+
+// OpenAPIRequestBody returns the request body object for the content types
+// accepted by ParseRequest: multipart/form-data and, only for forms without
+// File Fields, application/x-www-form-urlencoded and application/json.
+// If ref is not empty (e.g. "#/components/schemas/Person") the schema is
+// referenced instead of inlined, see OpenAPISchema.
+// The encodings describe arrays as repeated keys and Struct, Map and Fill
+// Fields as json strings. Since the entries of Collections are sent as keys with
+// their path in form bodies (e.g. "Addresses[0].City"), the schema of the form
+// bodies of forms with Collections is always inlined and describes these keys
+// as patternProperties.
+func (ø *FormHandler) OpenAPIRequestBody(ref string) *OpenAPIRequestBody {
+	schema := &Schema{Ref: ref}
+	if ref == "" {
+		schema = ø.OpenAPISchema()
+	}
+	formSchema := schema
+	for _, field := range ø.Fields {
+		if field.Type == Collection {
+			formSchema = ø.formSchema()
+			break
+		}
+	}
+
+	body := &OpenAPIRequestBody{Content: map[string]OpenAPIMediaType{}}
+	encoding := map[string]OpenAPIEncoding{}
+	explode := true
+
+	for _, field := range ø.Fields {
+		if field.Required {
+			body.Required = true
+		}
+		switch field.Type {
+		case IntArray, FloatArray, StringArray:
+			encoding[field.Name] = OpenAPIEncoding{Style: "form", Explode: &explode}
+		case Struct, Map, Fill:
+			encoding[field.Name] = OpenAPIEncoding{ContentType: "application/json"}
+		case File, FileArray:
+			if len(field.MimeTypes) > 0 {
+				encoding[field.Name] = OpenAPIEncoding{ContentType: strings.Join(field.MimeTypes, ", ")}
+			}
+		}
+	}
+
+	if len(encoding) == 0 {
+		encoding = nil
+	}
+	body.Content["multipart/form-data"] = OpenAPIMediaType{Schema: formSchema, Encoding: encoding}
+	// files can only be uploaded with multipart bodies, ParseJSON doesn't accept them
+	if !ø.hasFiles() {
+		body.Content["application/x-www-form-urlencoded"] = OpenAPIMediaType{Schema: formSchema, Encoding: encoding}
+		body.Content["application/json"] = OpenAPIMediaType{Schema: schema}
+	}
+	return body
+}
+
+// the schema of urlencoded and multipart bodies: like the OpenAPISchema, but the
+// Fields of the entries of Collections are keys with their path
+func (ø *FormHandler) formSchema() *Schema {
+	s := &Schema{Type: "object", Properties: map[string]*Schema{}}
+	for _, field := range ø.orderedFields() {
+		if field.Type == Collection {
+			field.Definition.prototype.entryPatterns(s, regexp.QuoteMeta(field.Name)+`\[[0-9]+\]\.`)
+			continue
+		}
+		s.Properties[field.Name] = field.JSONSchema()
+		if field.Required {
+			s.Required = append(s.Required, field.Name)
+		}
+	}
+	return s
+}
+
+// adds the Fields of an entry to the patternProperties of s, prefix is the
+// pattern of the path of the entry, e.g. `Addresses\[[0-9]+\]\.`
+func (ø *FormHandler) entryPatterns(s *Schema, prefix string) {
+	if s.PatternProperties == nil {
+		s.PatternProperties = map[string]*Schema{}
+	}
+	for _, field := range ø.orderedFields() {
+		if field.Type == Collection {
+			field.Definition.prototype.entryPatterns(s, prefix+regexp.QuoteMeta(field.Name)+`\[[0-9]+\]\.`)
+			continue
+		}
+		s.PatternProperties["^"+prefix+regexp.QuoteMeta(field.Name)+"$"] = field.JSONSchema()
+	}
+}
+
+// reports if the form or the entries of its Collections have File or FileArray Fields
+func (ø *FormHandler) hasFiles() bool {
+	for _, field := range ø.Fields {
+		switch field.Type {
+		case File, FileArray:
+			return true
+		case Collection:
+			if field.Definition.prototype.hasFiles() {
+				return true
+			}
+		}
+	}
+	return false
+}
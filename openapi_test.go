@@ -0,0 +1,81 @@
+package goform
+
+import (
+	"encoding/json"
+	h "github.com/metakeule/goh4"
+	. "github.com/metakeule/goh4/tag"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestOpenAPIRequestBody(t *testing.T) {
+	f := NewForm(
+		Required("Name", String, INPUT()),
+		Optional("Tags", StringArray, INPUT()),
+		Optional("Details", Map, TEXTAREA()),
+	)
+
+	b, _ := json.Marshal(f.OpenAPIRequestBody("#/components/schemas/Person"))
+	js := string(b)
+
+	for _, s := range []string{
+		`"required":true`,
+		`"application/x-www-form-urlencoded":{"schema":{"$ref":"#/components/schemas/Person"}`,
+		`"multipart/form-data":{"schema":{"$ref":"#/components/schemas/Person"}`,
+		`"Tags":{"style":"form","explode":true}`,
+		`"Details":{"contentType":"application/json"}`,
+	} {
+		if !strings.Contains(js, s) {
+			err(t, "missing in request body", js, s)
+		}
+	}
+
+	if schema := f.OpenAPISchema(); schema.Schema != "" || schema.Properties["Name"].Type != "string" {
+		err(t, "incorrect component schema", schema, "object without $schema")
+	}
+
+	f = NewForm(MimeTypes(Required("Doc", File, INPUT()), "application/pdf"))
+	body := f.OpenAPIRequestBody("")
+
+	if _, has := body.Content["application/x-www-form-urlencoded"]; has {
+		err(t, "forms with files can't be urlencoded", body.Content, "only multipart/form-data")
+	}
+
+	if _, has := body.Content["application/json"]; has {
+		err(t, "forms with files can't be sent as json", body.Content, "only multipart/form-data")
+	}
+
+	if enc := body.Content["multipart/form-data"].Encoding["Doc"]; enc.ContentType != "application/pdf" {
+		err(t, "incorrect encoding of Doc", enc.ContentType, "application/pdf")
+	}
+}
+
+func TestOpenAPIRequestBodyCollection(t *testing.T) {
+	f := NewForm(
+		Required("Name", String, INPUT()),
+		Optional("Addresses", addressDefinition, LEGEND(h.Text("Addresses"))),
+	)
+	body := f.OpenAPIRequestBody("#/components/schemas/Person")
+
+	if schema := body.Content["application/json"].Schema; schema.Ref != "#/components/schemas/Person" {
+		err(t, "the json body should reference the schema", schema, "$ref")
+	}
+
+	for _, ct := range []string{"application/x-www-form-urlencoded", "multipart/form-data"} {
+		schema := body.Content[ct].Schema
+		if schema.Ref != "" || schema.Properties["Name"] == nil || schema.Properties["Addresses"] != nil {
+			err(t, "the "+ct+" body should have the Fields as keys", schema, "Name")
+			continue
+		}
+		found := false
+		for pattern, s := range schema.PatternProperties {
+			if regexp.MustCompile(pattern).MatchString("Addresses[12].City") && s.Type == "string" {
+				found = true
+			}
+		}
+		if !found {
+			err(t, "the "+ct+" body should describe the keys of the entries", schema.PatternProperties, "Addresses[12].City")
+		}
+	}
+}
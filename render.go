@@ -0,0 +1,131 @@
+package goform
+
+import (
+	h "github.com/metakeule/goh4"
+	. "github.com/metakeule/goh4/tag"
+)
+
+// RenderSubmission writes the outcome of the last parsing into the html of
+// the form, so that it can be shown again after a failed submit:
+//
+//   - the raw submitted values are filled back into their Fields, including invalid ones
+//   - the errors of each Field are added as messages to the Field, its inputs get
+//     aria-invalid and the classes of the Theme for invalid inputs and labels
+//   - collapsed Groups that contain Fields with errors are expanded
+//   - the GeneralValidationErrors are added as a summary at the top of the form
+//
+// The messages are translated to the Locale of the form, see ErrorMessage.
+// It should be called after Parse, ParseFormValues or ParseRequest, the errors
+// of an earlier call are replaced.
+func (ø *FormHandler) RenderSubmission() {
+	for _, field := range ø.Fields {
+		field.clearErrors(ø)
+	}
+
+	for field, raw := range ø.Submitted {
+		if field.Type == File || field.Type == FileArray {
+			continue
+		}
+		field.setElementValues(raw, field.locale(ø))
+	}
+
+	for _, entries := range ø.Collections {
+		for _, entry := range entries {
+			entry.RenderSubmission()
+		}
+	}
+
+	for field, errs := range ø.FieldErrors {
+		field.renderErrors(ø, errs)
+	}
+	ø.openGroups(ø.Order)
+
+	ø.renderGeneralErrors()
+}
+
+// fills the summary of the GeneralValidationErrors, it is hidden if there are none
+func (ø *FormHandler) renderGeneralErrors() {
+	if ø.errorSummary == nil {
+		if len(ø.GeneralValidationErrors) == 0 {
+			return
+		}
+		ø.errorSummary = DIV(h.Attr("role", "alert"))
+		ø.errorSummary.AddClass(ø.theme().GeneralErrors()...)
+		ø.AddAtPosition(0, ø.errorSummary)
+	}
+	if len(ø.GeneralValidationErrors) == 0 {
+		ø.errorSummary.Clear()
+		ø.errorSummary.Add(h.Attr("hidden", "hidden"))
+		return
+	}
+	summary := UL()
+	for _, err := range ø.GeneralValidationErrors {
+		summary.Add(LI(h.Text(ø.ErrorMessage(err))))
+	}
+	ø.errorSummary.RemoveAttribute("hidden")
+	ø.errorSummary.SetContent(summary)
+}
+
+// the id of the element that holds the error messages of the Field
+func (ø *Field) errorsId() string {
+	return ø.Name + "-errors"
+}
+
+func (ø *Field) renderErrors(form *FormHandler, errs []error) {
+	if ø.Type == Collection {
+		// the errors of the entries are rendered by the entries
+		errs = ø.ownErrors(errs)
+	}
+	if len(errs) == 0 {
+		return
+	}
+	theme := form.theme()
+	describedBy := ø.errorsId()
+	if ø.Element.Any(h.Id(ø.helpId())) != nil {
+		describedBy = ø.helpId() + " " + describedBy
+	}
+	for _, el := range ø.inputs() {
+		el.Add(h.Attr("aria-invalid", "true", "aria-describedby", describedBy))
+		el.AddClass(theme.InvalidInput(ø, el)...)
+	}
+	if label := ø.Element.Any(h.Tag("label")); label != nil {
+		label.AddClass(theme.InvalidLabel(ø)...)
+	}
+	if ø.errorList == nil {
+		ø.errorList = UL(h.Id(ø.errorsId()))
+		ø.errorList.AddClass(theme.Errors(ø)...)
+		ø.Element.Add(ø.errorList)
+	}
+	ø.errorList.RemoveAttribute("hidden")
+	ø.errorList.Clear()
+	for _, err := range errs {
+		ø.errorList.Add(LI(h.Text(form.ErrorMessage(err))))
+	}
+}
+
+// removes the errors of an earlier RenderSubmission, the list of messages is hidden
+func (ø *Field) clearErrors(form *FormHandler) {
+	theme := form.theme()
+	for _, el := range ø.inputs() {
+		el.RemoveAttribute("aria-invalid")
+		el.RemoveAttribute("aria-describedby")
+		if ø.Element.Any(h.Id(ø.helpId())) != nil {
+			el.Add(h.Attr("aria-describedby", ø.helpId()))
+		}
+		for _, c := range theme.InvalidInput(ø, el) {
+			el.RemoveClass(c)
+		}
+		// the classes for invalid inputs may overlap with the normal ones
+		el.AddClass(ø.theme.Input(ø, el)...)
+	}
+	if label := ø.Element.Any(h.Tag("label")); label != nil {
+		for _, c := range theme.InvalidLabel(ø) {
+			label.RemoveClass(c)
+		}
+		label.AddClass(ø.theme.Label(ø)...)
+	}
+	if ø.errorList != nil {
+		ø.errorList.Clear()
+		ø.errorList.Add(h.Attr("hidden", "hidden"))
+	}
+}
@@ -0,0 +1,96 @@
+package goform
+
+import (
+	"fmt"
+	h "github.com/metakeule/goh4"
+	. "github.com/metakeule/goh4/tag"
+	"strings"
+	"testing"
+)
+
+func TestRenderSubmission(t *testing.T) {
+	f := NewForm(
+		Required("Name", String, LABEL("Name", INPUT())),
+		Optional("Age", Int, LABEL("Age", INPUT())),
+	)
+	f.Validation = func(ø *FormHandler) {
+		ø.AddValidationError(fmt.Errorf("something is wrong"))
+	}
+
+	if e := f.Parse(map[string]string{"Age": "old"}); e == nil {
+		t.Fatalf("expected errors")
+	}
+	f.RenderSubmission()
+
+	age := f.Field("Age").Element.Fields()[0]
+
+	if age.Attribute("value") != "old" {
+		err(t, "invalid value should be refilled", age.Attribute("value"), "old")
+	}
+
+	if age.Attribute("aria-invalid") != "true" {
+		err(t, "missing aria-invalid", age.Attribute("aria-invalid"), "true")
+	}
+
+	if !f.Field("Name").Element.Any(h.Tag("label")).HasClass(h.Class("error")) {
+		err(t, "missing error class on label of required field", "", "error")
+	}
+
+	html := f.String()
+	for _, s := range []string{`"old" is no int`, "required", "something is wrong"} {
+		if !strings.Contains(html, s) && !strings.Contains(html, strings.Replace(s, `"`, "&#34;", -1)) {
+			err(t, "missing message in html", html, s)
+		}
+	}
+}
+
+func TestRenderSubmissionTwice(t *testing.T) {
+	f := NewForm(
+		Required("Name", String, LABEL("Name", INPUT())),
+		Optional("Age", Int, LABEL("Age", INPUT())),
+	)
+	f.SetTheme(BootstrapTheme{})
+	f.Validation = func(ø *FormHandler) {
+		if ø.Ints[ø.Field("Age")] > 100 {
+			ø.AddValidationError(fmt.Errorf("something is wrong"))
+		}
+	}
+
+	f.Parse(map[string]string{"Age": "old"})
+	f.RenderSubmission()
+	f.RenderSubmission()
+
+	html := f.String()
+	if strings.Count(html, "<li>required</li>") != 1 || strings.Count(html, "no int") != 1 {
+		err(t, "the errors should not be duplicated", html, 1)
+	}
+
+	f.Reset()
+	f.Parse(map[string]string{"Name": "Donald", "Age": "144"})
+	f.RenderSubmission()
+
+	age := f.Field("Age").Element.Fields()[0]
+	if age.Attribute("aria-invalid") != "" || age.HasClass("is-invalid") || !age.HasClass("form-control") {
+		err(t, "the errors of the last render should be removed", age.String(), `class="form-control"`)
+	}
+
+	if f.Field("Name").Element.Any(h.Tag("label")).HasClass("text-danger") {
+		err(t, "the label should not be marked invalid anymore", f.Field("Name").Element.String(), "form-label")
+	}
+
+	html = f.String()
+	if strings.Contains(html, "<li>required</li>") || strings.Contains(html, "no int") {
+		err(t, "the old messages should be removed", html, "")
+	}
+
+	if strings.Count(html, "something is wrong") != 1 {
+		err(t, "the general error should be shown once", html, "something is wrong")
+	}
+
+	f.Reset()
+	f.Parse(map[string]string{"Name": "Donald", "Age": "44"})
+	f.RenderSubmission()
+	if strings.Contains(f.String(), "something is wrong") || f.errorSummary.Attribute("hidden") == "" {
+		err(t, "the summary should be hidden", f.String(), "hidden")
+	}
+}
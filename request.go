@@ -0,0 +1,80 @@
+package goform
+
+import (
+	"mime"
+	"net/http"
+)
+
+// the default for FormHandler.MaxMemory
+const DefaultMaxMemory = 32 << 20
+
+// ParseRequest parses the query string and the body of the given request
+// (urlencoded, multipart or json) and runs it through the same pipeline as
+// ParseFormValues. Values of the body take precedence over the query string,
+// json bodies are parsed like by ParseJSON. Uploaded files are parsed into Files
+// and FileArrays. If the form has no Locale, the error messages and labels are
+// translated to the locale of the Accept-Language header; numbers and dates are
+// still parsed without Locale, so that the client can't change their meaning.
+// If the form has a CSRF store, requests without a valid token (see AddCSRFToken
+// and CSRFHeaderName) are rejected with ErrInvalidCSRFToken before BeforeParsing.
+// A returned error that is not a result of the parsing, validation or action
+// pipeline comes from reading the request body.
+func (ø *FormHandler) ParseRequest(r *http.Request) (err error) {
+	ø.prepareRequest(r)
+
+	isJSON := mediaType(r) == "application/json"
+	if !isJSON {
+		if err = ø.readForm(r); err != nil {
+			return
+		}
+	}
+	if err = ø.verifyCSRF(r); err != nil {
+		return
+	}
+
+	if isJSON {
+		vals, err := decodeJSON(r.Body)
+		if err != nil {
+			return err
+		}
+		return ø.ParseSource(sources{vals, URLValues(r.URL.Query())})
+	}
+	ø.beforeParsing()
+	ø.parseSource(URLValues(r.Form))
+	if r.MultipartForm != nil {
+		ø.parseFiles(r.MultipartForm.File)
+	}
+	return ø.afterParsing()
+}
+
+// limits the body of the request and takes the locale from it
+func (ø *FormHandler) prepareRequest(r *http.Request) {
+	if ø.MaxBodySize > 0 && r.Body != nil {
+		r.Body = http.MaxBytesReader(nil, r.Body, ø.MaxBodySize)
+	}
+
+	ø.requestLocale = ""
+	if ø.Locale == "" {
+		ø.requestLocale = NegotiateLocale(r.Header.Get("Accept-Language"), ø.catalog().Locales())
+	}
+}
+
+// parses the query string and the urlencoded or multipart body of the request
+func (ø *FormHandler) readForm(r *http.Request) error {
+	if mediaType(r) == "multipart/form-data" {
+		maxMemory := ø.MaxMemory
+		if maxMemory <= 0 {
+			maxMemory = DefaultMaxMemory
+		}
+		return r.ParseMultipartForm(maxMemory)
+	}
+	return r.ParseForm()
+}
+
+func mediaType(r *http.Request) string {
+	mt, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err != nil {
+		return ""
+	}
+	return mt
+}
@@ -0,0 +1,95 @@
+package goform
+
+import (
+	"bytes"
+	. "github.com/metakeule/goh4/tag"
+	"mime/multipart"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func newRequestTestForm() *FormHandler {
+	return NewForm(
+		Required("Name", String, INPUT()),
+		Optional("Age", Int, INPUT()),
+		Optional("Tags", StringArray, INPUT()),
+	)
+}
+
+func TestParseRequestUrlencoded(t *testing.T) {
+	f := newRequestTestForm()
+	body := url.Values{"Name": {"Donald"}, "Tags": {"a", "b"}}.Encode()
+	r := httptest.NewRequest("POST", "/?Age=144", strings.NewReader(body))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	if e := f.ParseRequest(r); e != nil {
+		t.Fatalf("unexpected error: %s", e)
+	}
+
+	if f.Strings[f.Field("Name")] != "Donald" {
+		err(t, "incorrect Name", f.Strings[f.Field("Name")], "Donald")
+	}
+
+	if f.Ints[f.Field("Age")] != 144 {
+		err(t, "incorrect Age", f.Ints[f.Field("Age")], 144)
+	}
+
+	if len(f.StringArrays[f.Field("Tags")]) != 2 {
+		err(t, "incorrect Tags", f.StringArrays[f.Field("Tags")], []string{"a", "b"})
+	}
+}
+
+func TestParseRequestJSONQuery(t *testing.T) {
+	f := newRequestTestForm()
+	r := httptest.NewRequest("POST", "/?Age=144&Name=Daisy", strings.NewReader(`{"Name": "Donald", "Tags": ["a", "b"]}`))
+	r.Header.Set("Content-Type", "application/json")
+
+	if e := f.ParseRequest(r); e != nil {
+		t.Fatalf("unexpected error: %s", e)
+	}
+
+	if f.Strings[f.Field("Name")] != "Donald" {
+		err(t, "the body should take precedence", f.Strings[f.Field("Name")], "Donald")
+	}
+
+	if f.Ints[f.Field("Age")] != 144 {
+		err(t, "the query string should be parsed", f.Ints[f.Field("Age")], 144)
+	}
+
+	if len(f.StringArrays[f.Field("Tags")]) != 2 {
+		err(t, "incorrect Tags", f.StringArrays[f.Field("Tags")], []string{"a", "b"})
+	}
+}
+
+func TestParseRequestMultipart(t *testing.T) {
+	f := newRequestTestForm()
+	var buf bytes.Buffer
+	w := multipart.NewWriter(&buf)
+	w.WriteField("Name", "Donald")
+	w.WriteField("Age", "144")
+	w.Close()
+	r := httptest.NewRequest("POST", "/", &buf)
+	r.Header.Set("Content-Type", w.FormDataContentType())
+
+	if e := f.ParseRequest(r); e != nil {
+		t.Fatalf("unexpected error: %s", e)
+	}
+
+	if f.Ints[f.Field("Age")] != 144 {
+		err(t, "incorrect Age", f.Ints[f.Field("Age")], 144)
+	}
+}
+
+func TestParseRequestMaxBodySize(t *testing.T) {
+	f := newRequestTestForm()
+	f.MaxBodySize = 10
+	body := url.Values{"Name": {strings.Repeat("x", 100)}}.Encode()
+	r := httptest.NewRequest("POST", "/", strings.NewReader(body))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	if e := f.ParseRequest(r); e == nil {
+		t.Errorf("expected an error for a body larger than MaxBodySize")
+	}
+}
@@ -0,0 +1,197 @@
+package goform
+
+import (
+	"reflect"
+	"strings"
+)
+
+// the dialect of the schemas returned by JSONSchema
+const SchemaDialect = "https://json-schema.org/draft/2020-12/schema"
+
+// Schema is a json schema (draft 2020-12), restricted to the keywords goform needs
+type Schema struct {
+	Schema               string             `json:"$schema,omitempty"`
+	Ref                  string             `json:"$ref,omitempty"`
+	Title                string             `json:"title,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	ContentMediaType     string             `json:"contentMediaType,omitempty"`
+	Enum                 []interface{}      `json:"enum,omitempty"`
+	Minimum              *float64           `json:"minimum,omitempty"`
+	Maximum              *float64           `json:"maximum,omitempty"`
+	MinLength            *int               `json:"minLength,omitempty"`
+	MaxLength            *int               `json:"maxLength,omitempty"`
+	Pattern              string             `json:"pattern,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	MinItems             *int               `json:"minItems,omitempty"`
+	MaxItems             *int               `json:"maxItems,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	PatternProperties    map[string]*Schema `json:"patternProperties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+}
+
+// JSONSchema returns a json schema for the values accepted by ParseJSON:
+// an object with a property for every Field, see Field.JSONSchema.
+func (ø *FormHandler) JSONSchema() *Schema {
+	s := ø.objectSchema()
+	s.Schema = SchemaDialect
+	return s
+}
+
+func (ø *FormHandler) objectSchema() *Schema {
+	s := &Schema{Type: "object", Properties: map[string]*Schema{}}
+	for _, field := range ø.orderedFields() {
+		s.Properties[field.Name] = field.JSONSchema()
+		if field.Required {
+			s.Required = append(s.Required, field.Name)
+		}
+	}
+	return s
+}
+
+// JSONSchema returns the json schema for the value of the Field. Selections
+// become enums, Validators the corresponding keywords and Struct Fields the
+// schema of the struct returned by the Constructor (following json struct tags).
+func (ø *Field) JSONSchema() (s *Schema) {
+	switch ø.Type {
+	case Int:
+		s = &Schema{Type: "integer"}
+	case Float:
+		s = &Schema{Type: "number"}
+	case Bool:
+		s = &Schema{Type: "boolean"}
+	case String:
+		s = &Schema{Type: "string"}
+	case IntArray:
+		s = &Schema{Type: "array", Items: &Schema{Type: "integer"}}
+	case FloatArray:
+		s = &Schema{Type: "array", Items: &Schema{Type: "number"}}
+	case StringArray:
+		s = &Schema{Type: "array", Items: &Schema{Type: "string"}}
+	case Struct:
+		s = typeSchema(reflect.TypeOf(ø.Constructor()), map[reflect.Type]bool{})
+	case Map, Fill:
+		s = &Schema{Type: "object"}
+	case Date:
+		s = &Schema{Type: "string"}
+		if ø.TimeLayout() == DefaultLayouts[Date] {
+			s.Format = "date"
+		}
+	case Time, DateTime, Duration:
+		s = &Schema{Type: "string"}
+	case File:
+		s = &Schema{Type: "string", ContentMediaType: "application/octet-stream"}
+	case FileArray:
+		s = &Schema{Type: "array", Items: &Schema{Type: "string", ContentMediaType: "application/octet-stream"}}
+	case Collection:
+		s = &Schema{Type: "array", Items: ø.Definition.prototype.objectSchema()}
+	default:
+		s = &Schema{}
+	}
+
+	if ø.Selection != nil {
+		sel := reflect.ValueOf(ø.Selection)
+		for i := 0; i < sel.Len(); i++ {
+			s.Enum = append(s.Enum, sel.Index(i).Interface())
+		}
+	}
+
+	// rules for array elements go to the items
+	scalar := s
+	if s.Items != nil {
+		scalar = s.Items
+	}
+	for _, v := range ø.Validators {
+		switch r := v.(type) {
+		case MinRule:
+			scalar.Minimum = &r.Min
+		case MaxRule:
+			scalar.Maximum = &r.Max
+		case MinLengthRule:
+			scalar.MinLength = &r.Length
+		case MaxLengthRule:
+			scalar.MaxLength = &r.Length
+		case PatternRule:
+			scalar.Pattern = "^(?:" + r.Expr + ")$"
+		case MinItemsRule:
+			s.MinItems = &r.Count
+		case MaxItemsRule:
+			s.MaxItems = &r.Count
+		case EmailRule:
+			scalar.Format = "email"
+		case URLRule:
+			scalar.Format = "uri"
+		case UUIDRule:
+			scalar.Format = "uuid"
+		}
+	}
+	return
+}
+
+// the json schema for values of the given go type as encoding/json handles them
+func typeSchema(t reflect.Type, seen map[reflect.Type]bool) *Schema {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == timeType {
+		return &Schema{Type: "string", Format: "date-time"}
+	}
+	switch t.Kind() {
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			// []byte is encoded as base64 string
+			return &Schema{Type: "string"}
+		}
+		return &Schema{Type: "array", Items: typeSchema(t.Elem(), seen)}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: typeSchema(t.Elem(), seen)}
+	case reflect.Struct:
+		s := &Schema{Type: "object"}
+		if seen[t] {
+			// recursive types are not described any further
+			return s
+		}
+		seen[t] = true
+		defer delete(seen, t)
+		s.Properties = map[string]*Schema{}
+		structPropertySchemas(t, s.Properties, seen)
+		return s
+	case reflect.Interface:
+		return &Schema{}
+	}
+	if isNumber(t.Kind()) {
+		if isFloat(t.Kind()) {
+			return &Schema{Type: "number"}
+		}
+		return &Schema{Type: "integer"}
+	}
+	return &Schema{}
+}
+
+func structPropertySchemas(t reflect.Type, props map[string]*Schema, seen map[reflect.Type]bool) {
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		name := sf.Name
+		if tag := sf.Tag.Get("json"); tag != "" {
+			if tag == "-" {
+				continue
+			}
+			if n := strings.Split(tag, ",")[0]; n != "" {
+				name = n
+			}
+		}
+		if sf.Anonymous && sf.Type.Kind() == reflect.Struct && sf.Tag.Get("json") == "" {
+			structPropertySchemas(sf.Type, props, seen)
+			continue
+		}
+		if sf.PkgPath != "" {
+			continue
+		}
+		props[name] = typeSchema(sf.Type, seen)
+	}
+}
@@ -0,0 +1,51 @@
+package goform
+
+import (
+	"encoding/json"
+	. "github.com/metakeule/goh4/tag"
+	"strings"
+	"testing"
+)
+
+type schemaAddress struct {
+	Street string `json:"street"`
+	Zip    int    `json:"zip,omitempty"`
+	Secret string `json:"-"`
+	Next   *schemaAddress
+}
+
+func TestJSONSchema(t *testing.T) {
+	f := NewForm(
+		Validators(Required("Name", String, INPUT()), MaxLength(20)),
+		Selection(Optional("Age", Int, SELECT(OPTION("18"), OPTION("21"))), 18, 21),
+		Validators(Optional("Scores", FloatArray, INPUT()), Min(0), MaxItems(3)),
+		Optional("Address", Constructor(func() interface{} { return &schemaAddress{} }), TEXTAREA()),
+		Optional("Born", Date, INPUT()),
+	)
+
+	b, e := json.Marshal(f.JSONSchema())
+	if e != nil {
+		t.Fatalf("can't marshal schema: %s", e)
+	}
+	js := string(b)
+
+	for _, s := range []string{
+		`"$schema":"https://json-schema.org/draft/2020-12/schema"`,
+		`"required":["Name"]`,
+		`"Name":{"type":"string","maxLength":20}`,
+		`"Age":{"type":"integer","enum":[18,21]}`,
+		`"Scores":{"type":"array","items":{"type":"number","minimum":0},"maxItems":3}`,
+		`"street":{"type":"string"}`,
+		`"zip":{"type":"integer"}`,
+		`"Next":{"type":"object"}`,
+		`"Born":{"type":"string","format":"date"}`,
+	} {
+		if !strings.Contains(js, s) {
+			err(t, "missing in schema", js, s)
+		}
+	}
+
+	if strings.Contains(js, "Secret") {
+		err(t, "json:\"-\" fields should be skipped", js, "no Secret")
+	}
+}
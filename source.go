@@ -0,0 +1,201 @@
+package goform
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ValueSource provides the raw submitted values of Fields, see FormHandler.ParseSource
+type ValueSource interface {
+	// Values returns the values submitted for the Field, one per array element.
+	// ok is false if nothing was submitted for the Field.
+	Values(field *Field) (vals []string, ok bool)
+}
+
+// nativeSource is implemented by ValueSources with typed values, e.g. json.
+// native reports for each value returned by Values if it is a number or boolean
+// in the format of strconv, that must not be parsed with the locale of the Field.
+type nativeSource interface {
+	native(field *Field) []bool
+}
+
+// takes the values of a Field from the first source that has values for it
+type sources []ValueSource
+
+func (ø sources) Values(field *Field) (vals []string, ok bool) {
+	for _, src := range ø {
+		if vals, ok = src.Values(field); ok {
+			return
+		}
+	}
+	return
+}
+
+func (ø sources) native(field *Field) []bool {
+	for _, src := range ø {
+		if _, ok := src.Values(field); ok {
+			if ns, isNative := src.(nativeSource); isNative {
+				return ns.native(field)
+			}
+			return nil
+		}
+	}
+	return nil
+}
+
+// URLValues is a ValueSource for url.Values and other maps with one value per array element
+type URLValues map[string][]string
+
+func (ø URLValues) Values(field *Field) (vals []string, ok bool) {
+	vals, ok = ø[field.Name]
+	return
+}
+
+// FlatValues is a ValueSource for maps with one string per Field. The values
+// of array Fields are split at the Separator and trimmed. Without a Separator
+// they are split at ",", except for the numbers of IntArray and FloatArray
+// Fields whose locale has "," as decimal separator (e.g. "0,25;1,5" for "de"),
+// which are split at ";".
+type FlatValues struct {
+	Map       map[string]string
+	Separator string // "" means "," or ";", see above
+	Locale    string // the locale of the form, overwritten by the Locale of the Field
+}
+
+func (ø FlatValues) Values(field *Field) (vals []string, ok bool) {
+	v, ok := ø.Map[field.Name]
+	if !ok {
+		return
+	}
+	switch field.Type {
+	case IntArray, FloatArray, StringArray:
+		sep := ø.separator(field)
+		for _, str := range strings.Split(v, sep) {
+			vals = append(vals, strings.TrimSpace(str))
+		}
+		return
+	}
+	return []string{v}, true
+}
+
+func (ø FlatValues) separator(field *Field) string {
+	if ø.Separator != "" {
+		return ø.Separator
+	}
+	locale := field.Locale
+	if locale == "" {
+		locale = ø.Locale
+	}
+	return field.arraySeparator(locale)
+}
+
+// JSONValues is a ValueSource for decoded json objects. Numbers and booleans
+// are converted to strings, arrays for array Fields to one value per element.
+// Numbers are parsed independent of the locale and numbers or booleans given
+// for String and StringArray Fields are reported as invalid.
+// Objects and arrays given for other Fields are passed as json, so that
+// Struct, Map and Fill Fields get them as they are and scalar Fields report
+// them as invalid. null is the same as a missing value.
+// The Fields of Collection entries are looked up by their path in nested
+// arrays of objects, e.g. "Addresses[0].City" in {"Addresses":[{"City":"Bonn"}]}.
+type JSONValues map[string]interface{}
+
+func (ø JSONValues) Values(field *Field) (vals []string, ok bool) {
+	vals, _, ok = ø.values(field)
+	return
+}
+
+func (ø JSONValues) native(field *Field) (native []bool) {
+	_, native, _ = ø.values(field)
+	return
+}
+
+// returns the values of the Field and for each value if it is a json number or boolean
+func (ø JSONValues) values(field *Field) (vals []string, native []bool, ok bool) {
+	v, ok := ø.lookup(field.Name)
+	if !ok || v == nil {
+		return nil, nil, false
+	}
+	switch field.Type {
+	case Struct, Map, Fill:
+		if s, isString := v.(string); isString {
+			return []string{s}, []bool{false}, true
+		}
+		b, err := json.Marshal(v)
+		if err != nil {
+			return nil, nil, false
+		}
+		return []string{string(b)}, []bool{false}, true
+	}
+	if a, isArray := v.([]interface{}); isArray {
+		switch field.Type {
+		case IntArray, FloatArray, StringArray:
+			vals, native = []string{}, []bool{}
+			for _, e := range a {
+				vals = append(vals, jsonString(e))
+				native = append(native, isJSONScalar(e))
+			}
+			return vals, native, true
+		}
+	}
+	return []string{jsonString(v)}, []bool{isJSONScalar(v)}, true
+}
+
+// reports if the decoded json value is a number or boolean
+func isJSONScalar(v interface{}) bool {
+	switch v.(type) {
+	case float64, json.Number, bool:
+		return true
+	}
+	return false
+}
+
+// returns the value for the name, which may be a path like "Addresses[0].City"
+func (ø JSONValues) lookup(name string) (v interface{}, ok bool) {
+	if v, ok = ø[name]; ok {
+		return
+	}
+	v = map[string]interface{}(ø)
+	for _, part := range strings.Split(strings.Replace(name, "[", ".[", -1), ".") {
+		if strings.HasPrefix(part, "[") && strings.HasSuffix(part, "]") {
+			a, isArray := v.([]interface{})
+			i, err := strconv.Atoi(part[1 : len(part)-1])
+			if !isArray || err != nil || i < 0 || i >= len(a) {
+				return nil, false
+			}
+			v = a[i]
+			continue
+		}
+		m, isObject := v.(map[string]interface{})
+		if !isObject {
+			return nil, false
+		}
+		if v, ok = m[part]; !ok {
+			return nil, false
+		}
+	}
+	return v, true
+}
+
+// the string representation of a decoded json scalar
+func jsonString(v interface{}) string {
+	switch val := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return val
+	case float64:
+		return strconv.FormatFloat(val, 'f', -1, 64)
+	case json.Number:
+		return val.String()
+	case bool:
+		return strconv.FormatBool(val)
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(b)
+}
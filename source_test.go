@@ -0,0 +1,56 @@
+package goform
+
+import (
+	. "github.com/metakeule/goh4/tag"
+	"reflect"
+	"testing"
+)
+
+func TestValueSourcesAreEquivalent(t *testing.T) {
+	sources := map[string]ValueSource{
+		"url": URLValues{
+			"Age":   {"144"},
+			"Name":  {""},
+			"Ints":  {"1", " 2", "3"},
+			"Tags":  {"a", "b"},
+			"Ratio": {"0.5"},
+		},
+		"flat": FlatValues{Map: map[string]string{
+			"Age":   "144",
+			"Name":  "",
+			"Ints":  "1; 2;3",
+			"Tags":  "a;b",
+			"Ratio": "0.5",
+		}, Separator: ";"},
+		"json": JSONValues{
+			"Age":   144.0,
+			"Name":  nil,
+			"Ints":  []interface{}{1.0, 2.0, "3"},
+			"Tags":  []interface{}{"a", "b"},
+			"Ratio": 0.5,
+		},
+	}
+
+	results := map[string]map[string]interface{}{}
+	for name, src := range sources {
+		f := NewForm(
+			Optional("Age", Int, INPUT()),
+			Optional("Name", String, INPUT()),
+			Optional("Ints", IntArray, INPUT()),
+			Optional("Tags", StringArray, INPUT()),
+			Optional("Ratio", Float, INPUT()),
+		)
+		if e := f.ParseSource(src); e != nil {
+			t.Fatalf("unexpected error for %s source: %s", name, e)
+		}
+		results[name] = f.Map()
+	}
+
+	if !reflect.DeepEqual(results["url"], results["flat"]) || !reflect.DeepEqual(results["url"], results["json"]) {
+		t.Errorf("sources differ:\nurl:  %v\nflat: %v\njson: %v", results["url"], results["flat"], results["json"])
+	}
+
+	if results["url"]["Name"] != nil {
+		err(t, "empty Name should not be filled", results["url"]["Name"], nil)
+	}
+}
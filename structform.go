@@ -0,0 +1,228 @@
+package goform
+
+import (
+	"fmt"
+	h "github.com/metakeule/goh4"
+	. "github.com/metakeule/goh4/tag"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	timeType         = reflect.TypeOf(time.Time{})
+	durationType     = reflect.TypeOf(time.Duration(0))
+	uploadedFileType = reflect.TypeOf(&UploadedFile{})
+	jsonMapType      = reflect.TypeOf(map[string]interface{}{})
+)
+
+// NewFormFromStruct creates a form with a Field for each exported field of the
+// struct v (or the struct v points to), in the order of the struct fields.
+// The Fields are configured by struct tags:
+//
+//	form:"name,required"   the Field name (defaults to the struct field name) and options:
+//	                       required, date, time or datetime (the latter for time.Time fields)
+//	label:"Your Name"      the text of the label (defaults to the Field name)
+//	options:"a,b,c"        the allowed values, rendered as select
+//	widget:"textarea"      textarea, select or the type of the input (e.g. password, hidden)
+//
+// Fields with `form:"-"` are skipped, embedded structs are treated as if their
+// fields were fields of v. It panics if v is no struct or a field has a type
+// that can't be mapped to a Type.
+func NewFormFromStruct(v interface{}) (ø *FormHandler) {
+	t := reflect.TypeOf(v)
+	if t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		panic(fmt.Sprintf("can't create form from %T: need a struct", v))
+	}
+	ø = NewForm()
+	for _, field := range structFields(t) {
+		ø.AddField(field)
+	}
+	return
+}
+
+func structFields(t reflect.Type) (fields []*Field) {
+	fields = []*Field{}
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if sf.Anonymous && sf.Type.Kind() == reflect.Struct {
+			fields = append(fields, structFields(sf.Type)...)
+			continue
+		}
+		if sf.PkgPath != "" {
+			continue
+		}
+		name := fieldName(sf)
+		if name == "-" {
+			continue
+		}
+		fields = append(fields, structField(name, sf))
+	}
+	return
+}
+
+func structField(name string, sf reflect.StructField) (field *Field) {
+	opts := strings.Split(sf.Tag.Get("form"), ",")[1:]
+	required := false
+	for _, o := range opts {
+		if o == "required" {
+			required = true
+		}
+	}
+	typer := structFieldType(name, sf.Type, opts)
+
+	var options []string
+	if o := sf.Tag.Get("options"); o != "" {
+		options = strings.Split(o, ",")
+	}
+
+	label := sf.Tag.Get("label")
+	if label == "" {
+		label = name
+	}
+
+	html := LABEL(SPAN(label), structFieldWidget(typer.Type(), sf.Tag.Get("widget"), options))
+	if required {
+		field = Required(name, typer, html)
+	} else {
+		field = Optional(name, typer, html)
+	}
+
+	if options != nil {
+		vals := []interface{}{}
+		for _, o := range options {
+			vals = append(vals, selectionValue(field.Type, o))
+		}
+		Selection(field, vals...)
+	}
+	return
+}
+
+// the Type (or Constructor) for a struct field of the given type
+func structFieldType(name string, t reflect.Type, opts []string) Typer {
+	for _, o := range opts {
+		switch o {
+		case "date":
+			return Date
+		case "time":
+			return Time
+		case "datetime":
+			return DateTime
+		}
+	}
+
+	switch t {
+	case timeType:
+		return DateTime
+	case durationType:
+		return Duration
+	case uploadedFileType:
+		return File
+	case reflect.SliceOf(uploadedFileType):
+		return FileArray
+	case jsonMapType:
+		return Map
+	}
+
+	switch t.Kind() {
+	case reflect.Ptr:
+		if t.Elem().Kind() == reflect.Struct {
+			return structConstructor(t.Elem())
+		}
+		return structFieldType(name, t.Elem(), opts)
+	case reflect.Struct:
+		return structConstructor(t)
+	case reflect.Bool:
+		return Bool
+	case reflect.String:
+		return String
+	case reflect.Slice:
+		switch {
+		case t.Elem().Kind() == reflect.String:
+			return StringArray
+		case isNumber(t.Elem().Kind()) && isFloat(t.Elem().Kind()):
+			return FloatArray
+		case isNumber(t.Elem().Kind()):
+			return IntArray
+		}
+	default:
+		switch {
+		case isNumber(t.Kind()) && isFloat(t.Kind()):
+			return Float
+		case isNumber(t.Kind()):
+			return Int
+		}
+	}
+	panic(fmt.Sprintf("can't create field %s: no Type for %s", name, t))
+}
+
+func isFloat(k reflect.Kind) bool {
+	return k == reflect.Float32 || k == reflect.Float64
+}
+
+func structConstructor(t reflect.Type) Constructor {
+	return Constructor(func() interface{} { return reflect.New(t).Interface() })
+}
+
+// the html element used to enter the value of a struct field
+func structFieldWidget(t Type, widget string, options []string) *h.Element {
+	if widget == "" {
+		switch {
+		case options != nil:
+			widget = "select"
+		case t == Map || t == Struct || t == Fill:
+			widget = "textarea"
+		case t == Bool:
+			widget = "checkbox"
+		}
+	}
+
+	switch widget {
+	case "textarea":
+		return TEXTAREA()
+	case "select":
+		sel := SELECT()
+		if t == IntArray || t == FloatArray || t == StringArray {
+			sel.Add(h.Attr("multiple", "multiple"))
+		}
+		for _, o := range options {
+			sel.Add(OPTION(h.Attr("value", o), h.Text(o)))
+		}
+		return sel
+	case "":
+		switch t {
+		case Int:
+			return INPUT(h.Attr("type", "number"))
+		case Float:
+			return INPUT(h.Attr("type", "number", "step", "any"))
+		case Date, Time, DateTime, File, FileArray:
+			// the type is set by the Field
+			return INPUT()
+		}
+		return INPUT(h.Attr("type", "text"))
+	}
+	return INPUT(h.Attr("type", widget))
+}
+
+// converts an option given in a struct tag to a value for Selection
+func selectionValue(t Type, option string) interface{} {
+	switch t {
+	case Int:
+		i, err := strconv.Atoi(option)
+		if err != nil {
+			panic(fmt.Sprintf("option %#v is no int", option))
+		}
+		return i
+	case Float:
+		f, err := strconv.ParseFloat(option, 32)
+		if err != nil {
+			panic(fmt.Sprintf("option %#v is no float", option))
+		}
+		return f
+	}
+	return option
+}
@@ -0,0 +1,89 @@
+package goform
+
+import (
+	h "github.com/metakeule/goh4"
+	"strings"
+	"testing"
+	"time"
+)
+
+type structFormPerson struct {
+	Name     string `form:"name,required" label:"Your Name"`
+	Age      int    `form:"age" options:"18,21,65"`
+	Height   *float32
+	Bio      string    `widget:"textarea"`
+	Born     time.Time `form:"born,date"`
+	Admin    bool
+	Address  *Address
+	internal string
+	Skipped  string `form:"-"`
+}
+
+func TestNewFormFromStruct(t *testing.T) {
+	f := NewFormFromStruct(&structFormPerson{})
+
+	expected := map[string]Type{
+		"name":    String,
+		"age":     Int,
+		"Height":  Float,
+		"Bio":     String,
+		"born":    Date,
+		"Admin":   Bool,
+		"Address": Struct,
+	}
+
+	if len(f.Fields) != len(expected) {
+		err(t, "incorrect number of fields", len(f.Fields), len(expected))
+	}
+
+	for name, tp := range expected {
+		if fld := f.Field(name); fld == nil || fld.Type != tp {
+			err(t, "incorrect field "+name, fld, tp)
+		}
+	}
+
+	if !f.Field("name").Required || f.Field("age").Required {
+		err(t, "only name should be required", f.Field("age").Required, false)
+	}
+
+	if sel, ok := f.Field("age").Selection.([]int); !ok || len(sel) != 3 || sel[1] != 21 {
+		err(t, "incorrect selection of age", f.Field("age").Selection, []int{18, 21, 65})
+	}
+
+	html := f.String()
+	for _, s := range []string{"Your Name", "<select", "<textarea", `type="date"`, `type="checkbox"`} {
+		if !strings.Contains(html, s) {
+			err(t, "missing in html", html, s)
+		}
+	}
+
+	_ = f.Parse(map[string]string{"name": "Donald", "age": "21", "Address": `{"City": "Entenhausen"}`})
+
+	var p structFormPerson
+	if e := f.Bind(&p); e != nil {
+		t.Fatalf("unexpected error: %s", e)
+	}
+
+	if p.Name != "Donald" || p.Age != 21 || p.Address.City != "Entenhausen" {
+		err(t, "incorrect bound struct", p, "Donald, 21, Entenhausen")
+	}
+}
+
+func TestStructFormOptionalSelection(t *testing.T) {
+	f := NewFormFromStruct(&structFormPerson{})
+
+	for _, o := range f.Field("age").Element.All(h.Tag("option")) {
+		if o.Attribute("value") == "" {
+			err(t, "the options should have a value", o.String(), `<option value="18">`)
+		}
+	}
+
+	if e := f.Parse(map[string]string{"name": "Donald"}); e != nil {
+		err(t, "an empty optional selection should be valid", e, nil)
+	}
+
+	f.Reset()
+	if e := f.Parse(map[string]string{"name": "Donald", "age": "20"}); f.FieldErrors[f.Field("age")] == nil {
+		err(t, "a value that is not in the selection should be invalid", e, CodeNotInSelection)
+	}
+}
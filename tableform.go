@@ -1,7 +1,6 @@
 package goform
 
 import (
-	"fmt"
 	h "github.com/metakeule/goh4"
 	. "github.com/metakeule/goh4/tag"
 	"github.com/metakeule/pgsql"
@@ -23,6 +22,10 @@ func getType(in pgsql.Type) (out Type) {
 		return Float
 	case pgsql.BoolType:
 		return Bool
+	case pgsql.DateType:
+		return Date
+	case pgsql.TimeType:
+		return Time
 	}
 	return String
 }
@@ -57,7 +60,7 @@ func GetElement(in *pgsql.Field) (out *h.Element) {
 		}
 		return INPUT(h.Attr("type", "text"))
 	case pgsql.DateType:
-		return INPUT(h.Attr("type", "text"), h.Class("date"))
+		return INPUT(h.Attr("type", "date"), h.Class("date"))
 	case pgsql.TimeType:
 		return INPUT(h.Attr("type", "time"))
 	}
@@ -132,15 +135,11 @@ func (ø *TableForm) SetValues(row *pgsql.Row) {
 			if elem.Tag() == "textarea" {
 				elem.Add(v)
 			} else {
-				//tp := elem.Attribute("type")
-				//if tp == "date" {
-				if elem.HasClass("date") {
+				if fld := ø.Field(k); fld.Type == Date || fld.Type == DateTime {
 					var tme time.Time
 					field := row.Table.Field(k)
 					row.Get(field, &tme)
-					year, month, day := tme.Date()
-					// %02.0f.%02.0f.%4.0f
-					v = fmt.Sprintf("%4.0f-%02.0f-%02.0f", float64(year), float64(int(month)), float64(day))
+					v = fld.FormatTime(tme)
 				}
 				elem.Add(h.Attr("value", v))
 			}
@@ -216,7 +215,7 @@ func (ø *TableForm) getElement(in *pgsql.Field) (out *h.Element) {
 	case pgsql.IntType:
 		return INPUT(h.Attr("type", "number"))
 	case pgsql.DateType:
-		return INPUT(h.Class("date"), h.Attr("type", "text"))
+		return INPUT(h.Class("date"), h.Attr("type", "date"))
 	case pgsql.TimeType:
 		return INPUT(h.Attr("type", "time"))
 	}
@@ -242,13 +241,11 @@ func (ø *TableForm) getElement(in *pgsql.Field) (out *h.Element) {
 }
 
 func (ø *TableForm) Unrequire(fld string) {
-	e := ø.Label(fld)
-	e.RemoveClass("required")
 	ø.FieldElement(fld).RemoveAttribute("required")
 	field := ø.Field(fld)
 	if field.Required {
 		ø.RemoveFieldFromRequired(field)
-		field.Required = false
+		field.restyle(func() { field.Required = false })
 	}
 }
 
@@ -268,13 +265,11 @@ func (ø *TableForm) FieldElement(fld string) *h.Element {
 }
 
 func (ø *TableForm) Require(fld string) {
-	e := ø.Label(fld)
-	e.AddClass("required")
 	ø.FieldElement(fld).Add(h.Attr("required", "required"))
 	field := ø.Field(fld)
 	if !field.Required {
 		ø.AddFieldToRequired(field)
-		field.Required = true
+		field.restyle(func() { field.Required = true })
 	}
 }
 
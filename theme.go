@@ -0,0 +1,178 @@
+package goform
+
+import (
+	h "github.com/metakeule/goh4"
+	. "github.com/metakeule/goh4/tag"
+)
+
+// Theme provides the css classes of the elements of a form. It is selected per
+// form with SetTheme or by passing it to NewForm, FormHandlers without a Theme
+// use PlainTheme.
+type Theme interface {
+	Input(field *Field, input *h.Element) []h.Class        // the inputs, selects and textareas of a Field
+	Label(field *Field) []h.Class                          // the label of a Field
+	InvalidInput(field *Field, input *h.Element) []h.Class // added to the inputs of a Field with errors
+	InvalidLabel(field *Field) []h.Class                   // added to the label of a Field with errors
+	Errors(field *Field) []h.Class                         // the list of error messages of a Field
+	GeneralErrors() []h.Class                              // the summary of the GeneralValidationErrors
+	Help(field *Field) []h.Class                           // the help text of a Field, see Help
+	Button() []h.Class                                     // the submit button, see AddSubmitButton
+}
+
+// PlainTheme keeps to semantic html and adds only the goform classes for custom css:
+// field and required for inputs and labels, error for labels of invalid Fields,
+// field-errors, errors, help and submit.
+type PlainTheme struct{}
+
+func (PlainTheme) Input(field *Field, input *h.Element) []h.Class {
+	if field.Required && input == field.Element.Fields()[0] {
+		return []h.Class{"field", "required"}
+	}
+	return []h.Class{"field"}
+}
+
+func (PlainTheme) Label(field *Field) []h.Class {
+	if field.Required {
+		return []h.Class{"required"}
+	}
+	return nil
+}
+
+func (PlainTheme) InvalidInput(field *Field, input *h.Element) []h.Class { return nil }
+func (PlainTheme) InvalidLabel(field *Field) []h.Class                   { return []h.Class{"error"} }
+func (PlainTheme) Errors(field *Field) []h.Class                         { return []h.Class{"field-errors"} }
+func (PlainTheme) GeneralErrors() []h.Class                              { return []h.Class{"errors"} }
+func (PlainTheme) Help(field *Field) []h.Class                           { return []h.Class{"help"} }
+func (PlainTheme) Button() []h.Class                                     { return []h.Class{"submit"} }
+
+// BootstrapTheme uses the form classes of Bootstrap 5
+type BootstrapTheme struct{}
+
+func (BootstrapTheme) Input(field *Field, input *h.Element) []h.Class {
+	switch {
+	case input.Tag() == "select":
+		return []h.Class{"form-select"}
+	case isCheck(input):
+		return []h.Class{"form-check-input"}
+	case input.Attribute("type") == "range":
+		return []h.Class{"form-range"}
+	}
+	return []h.Class{"form-control"}
+}
+
+func (BootstrapTheme) Label(field *Field) []h.Class { return []h.Class{"form-label"} }
+func (BootstrapTheme) InvalidInput(field *Field, input *h.Element) []h.Class {
+	return []h.Class{"is-invalid"}
+}
+func (BootstrapTheme) InvalidLabel(field *Field) []h.Class { return []h.Class{"text-danger"} }
+func (BootstrapTheme) Errors(field *Field) []h.Class {
+	// the list is no sibling of the input, so it has to be shown explicitly
+	return []h.Class{"invalid-feedback", "d-block"}
+}
+func (BootstrapTheme) GeneralErrors() []h.Class    { return []h.Class{"alert", "alert-danger"} }
+func (BootstrapTheme) Help(field *Field) []h.Class { return []h.Class{"form-text"} }
+func (BootstrapTheme) Button() []h.Class           { return []h.Class{"btn", "btn-primary"} }
+
+// TailwindTheme uses tailwind utility classes
+type TailwindTheme struct{}
+
+func (TailwindTheme) Input(field *Field, input *h.Element) []h.Class {
+	if isCheck(input) {
+		return []h.Class{"h-4", "w-4", "rounded", "border-gray-300"}
+	}
+	return []h.Class{"block", "w-full", "rounded-md", "border", "border-gray-300", "px-3", "py-2"}
+}
+
+func (TailwindTheme) Label(field *Field) []h.Class {
+	return []h.Class{"block", "text-sm", "font-medium", "text-gray-700"}
+}
+func (TailwindTheme) InvalidInput(field *Field, input *h.Element) []h.Class {
+	return []h.Class{"border-red-500"}
+}
+func (TailwindTheme) InvalidLabel(field *Field) []h.Class { return []h.Class{"text-red-600"} }
+func (TailwindTheme) Errors(field *Field) []h.Class {
+	return []h.Class{"mt-1", "text-sm", "text-red-600"}
+}
+func (TailwindTheme) GeneralErrors() []h.Class {
+	return []h.Class{"rounded-md", "bg-red-50", "p-4", "text-sm", "text-red-700"}
+}
+func (TailwindTheme) Help(field *Field) []h.Class {
+	return []h.Class{"mt-1", "text-sm", "text-gray-500"}
+}
+func (TailwindTheme) Button() []h.Class {
+	return []h.Class{"rounded-md", "bg-indigo-600", "px-4", "py-2", "text-white", "hover:bg-indigo-500"}
+}
+
+func isCheck(input *h.Element) bool {
+	t := input.Attribute("type")
+	return input.Tag() == "input" && (t == "checkbox" || t == "radio")
+}
+
+func (ø *FormHandler) theme() Theme {
+	if ø.Theme != nil {
+		return ø.Theme
+	}
+	return PlainTheme{}
+}
+
+// SetTheme replaces the classes of the current Theme of all Fields by those of the given one.
+// It should be called before AddSubmitButton and RenderSubmission.
+func (ø *FormHandler) SetTheme(t Theme) {
+	ø.Theme = t
+	for _, field := range ø.Fields {
+		field.setTheme(t)
+	}
+}
+
+// Help adds the given help text to the Field, its inputs are described by it
+func Help(field *Field, text string) *Field {
+	help := SMALL(h.Id(field.helpId()), h.Text(text))
+	help.AddClass(field.theme.Help(field)...)
+	field.Element.Add(help)
+	for _, el := range field.Element.Fields() {
+		el.Add(h.Attr("aria-describedby", field.helpId()))
+	}
+	return field
+}
+
+// the id of the element that holds the help text of the Field
+func (ø *Field) helpId() string {
+	return ø.Name + "-help"
+}
+
+// replaces the classes of the current theme of the Field by those of the given one
+func (ø *Field) setTheme(t Theme) {
+	ø.restyle(func() { ø.theme = t })
+}
+
+// removes the classes of the theme, runs change and adds the classes again,
+// for changes that affect the classes, e.g. of Required
+func (ø *Field) restyle(change func()) {
+	ø.styleElements(ø.theme, (*h.Element).RemoveClass)
+	change()
+	ø.styleElements(ø.theme, func(el *h.Element, c h.Class) { el.AddClass(c) })
+}
+
+func (ø *Field) styleElements(t Theme, fn func(*h.Element, h.Class)) {
+	if t == nil {
+		return
+	}
+	if ø.Type == Collection {
+		ø.styleEntries(t, fn)
+	}
+	for _, el := range ø.inputs() {
+		for _, c := range t.Input(ø, el) {
+			fn(el, c)
+		}
+	}
+	if label := ø.Element.Any(h.Tag("label")); label != nil {
+		for _, c := range t.Label(ø) {
+			fn(label, c)
+		}
+	}
+	if help := ø.Element.Any(h.Id(ø.helpId())); help != nil {
+		for _, c := range t.Help(ø) {
+			fn(help, c)
+		}
+	}
+}
@@ -0,0 +1,73 @@
+package goform
+
+import (
+	h "github.com/metakeule/goh4"
+	. "github.com/metakeule/goh4/tag"
+	"testing"
+)
+
+func TestPlainTheme(t *testing.T) {
+	f := NewForm(Required("Name", String, LABEL(h.Text("Name"), INPUT())))
+	input := f.Field("Name").Element.Fields()[0]
+
+	if !input.HasClass("field") || !input.HasClass("required") {
+		err(t, "missing plain classes on input", input.String(), "field required")
+	}
+
+	if btn := f.AddSubmitButton("Save"); !btn.HasClass("submit") || btn.HasClass("btn") {
+		err(t, "incorrect classes of submit button", btn.String(), "submit")
+	}
+}
+
+func TestSetTheme(t *testing.T) {
+	f := NewForm(
+		BootstrapTheme{},
+		Help(Required("Name", String, LABEL(h.Text("Name"), INPUT())), "your full name"),
+		Optional("Color", String, SELECT(OPTION(h.Text("red")))),
+	)
+	name := f.Field("Name")
+	input := name.Element.Fields()[0]
+	label := name.Element.Any(h.Tag("label"))
+
+	if !input.HasClass("form-control") || input.HasClass("field") || input.HasClass("required") {
+		err(t, "incorrect classes of input", input.String(), "form-control")
+	}
+
+	if !label.HasClass("form-label") || label.HasClass("required") {
+		err(t, "incorrect classes of label", label.String(), "form-label")
+	}
+
+	if sel := f.Field("Color").Element.Fields()[0]; !sel.HasClass("form-select") {
+		err(t, "incorrect classes of select", sel.String(), "form-select")
+	}
+
+	if help := name.Element.Any(h.Id("Name-help")); help == nil || !help.HasClass("form-text") {
+		err(t, "incorrect help text", name.Element.String(), "form-text")
+	}
+
+	if btn := f.AddSubmitButton("Save"); !btn.HasClass("btn") || !btn.HasClass("btn-primary") {
+		err(t, "incorrect classes of submit button", btn.String(), "btn btn-primary")
+	}
+
+	f.SetTheme(TailwindTheme{})
+
+	if input.HasClass("form-control") || !input.HasClass("rounded-md") {
+		err(t, "classes of the old theme should be replaced", input.String(), "tailwind classes")
+	}
+
+	f.Parse(map[string]string{})
+	f.AddValidationError(ErrInvalid)
+	f.RenderSubmission()
+
+	if !input.HasClass("border-red-500") || input.Attribute("aria-describedby") != "Name-help Name-errors" {
+		err(t, "incorrect invalid input", input.String(), "border-red-500 described by help and errors")
+	}
+
+	if errs := f.Any(h.Id("Name-errors")); errs == nil || !errs.HasClass("text-red-600") {
+		err(t, "incorrect error list", f.String(), "text-red-600")
+	}
+
+	if general := f.Any(h.Attr("role", "alert")); general == nil || !general.HasClass("bg-red-50") {
+		err(t, "incorrect general errors", f.String(), "bg-red-50")
+	}
+}
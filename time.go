@@ -0,0 +1,99 @@
+package goform
+
+import (
+	h "github.com/metakeule/goh4"
+	"time"
+)
+
+// the layouts used for parsing and rendering Date, Time and DateTime Fields
+// if the Field has no Layout. They match the value formats of the
+// corresponding html5 inputs.
+var DefaultLayouts = map[Type]string{
+	Date:     "2006-01-02",
+	Time:     "15:04",
+	DateTime: "2006-01-02T15:04",
+}
+
+// the layout used to parse and format the values of the Field
+func (ø *Field) TimeLayout() string {
+	if ø.Layout != "" {
+		return ø.Layout
+	}
+	return DefaultLayouts[ø.Type]
+}
+
+// FormatTime formats t with the layout of the Field
+func (ø *Field) FormatTime(t time.Time) string {
+	return t.Format(ø.TimeLayout())
+}
+
+// Layout sets the layout used to parse and format the values of the given Date, Time or DateTime Field
+func Layout(field *Field, layout string) *Field {
+	field.Layout = layout
+	return field
+}
+
+// TimeRange restricts the values of the given Date, Time or DateTime Field to be
+// between min and max (both inclusive). A zero time means no limit.
+// The min and max attributes of the input are set accordingly.
+func TimeRange(field *Field, min time.Time, max time.Time) *Field {
+	field.MinTime = min
+	field.MaxTime = max
+	if fs := field.Element.Fields(); len(fs) > 0 {
+		if !min.IsZero() {
+			fs[0].Add(h.Attr("min", field.FormatTime(min)))
+		}
+		if !max.IsZero() {
+			fs[0].Add(h.Attr("max", field.FormatTime(max)))
+		}
+	}
+	return field
+}
+
+// DurationRange restricts the values of the given Duration Field to be
+// between min and max (both inclusive). 0 means no limit.
+func DurationRange(field *Field, min time.Duration, max time.Duration) *Field {
+	field.MinDuration = min
+	field.MaxDuration = max
+	return field
+}
+
+func (ø *FormHandler) parseTime(k *Field, v string) {
+	if k.Type == Duration {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			ø.AddFieldError(k, newValidationError(k, CodeNotDuration, v, nil, "%#v is no duration", v))
+		}
+		ø.Durations[k] = d
+		return
+	}
+	t, layout, err := ø.parseTimeValue(k, v)
+	if err != nil {
+		ø.AddFieldError(k, newValidationError(k, CodeNotTime, v, Params{"layout": layout}, "%#v does not match the layout %#v", v, layout))
+	}
+	ø.Times[k] = t
+}
+
+func (ø *Field) checkTimeRange(form *FormHandler) {
+	if !form.IsFilledField(ø) {
+		return
+	}
+	switch ø.Type {
+	case Date, Time, DateTime:
+		val := form.Times[ø]
+		if !ø.MinTime.IsZero() && val.Before(ø.MinTime) {
+			form.AddFieldError(ø, newValidationError(ø, CodeTooEarly, val, Params{"min": ø.FormatTime(ø.MinTime)}, "%s is before %s", ø.FormatTime(val), ø.FormatTime(ø.MinTime)))
+		}
+		if !ø.MaxTime.IsZero() && val.After(ø.MaxTime) {
+			form.AddFieldError(ø, newValidationError(ø, CodeTooLate, val, Params{"max": ø.FormatTime(ø.MaxTime)}, "%s is after %s", ø.FormatTime(val), ø.FormatTime(ø.MaxTime)))
+		}
+	case Duration:
+		val := form.Durations[ø]
+		if ø.MinDuration != 0 && val < ø.MinDuration {
+			form.AddFieldError(ø, newValidationError(ø, CodeDurationTooShort, val, Params{"min": ø.MinDuration.String()}, "%s is shorter than %s", val, ø.MinDuration))
+		}
+		if ø.MaxDuration != 0 && val > ø.MaxDuration {
+			form.AddFieldError(ø, newValidationError(ø, CodeDurationTooLong, val, Params{"max": ø.MaxDuration.String()}, "%s is longer than %s", val, ø.MaxDuration))
+		}
+	}
+}
@@ -0,0 +1,45 @@
+package goform
+
+import (
+	. "github.com/metakeule/goh4/tag"
+	"testing"
+	"time"
+)
+
+func TestParseTimes(t *testing.T) {
+	min := time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+	f := NewForm(
+		TimeRange(Required("Birthday", Date, INPUT()), min, time.Time{}),
+		Optional("Meeting", DateTime, INPUT()),
+		Optional("Start", Time, INPUT()),
+		DurationRange(Optional("Timeout", Duration, INPUT()), 0, time.Hour),
+	)
+
+	_ = f.Parse(map[string]string{
+		"Birthday": "1999-12-31",
+		"Meeting":  "2013-05-17T14:30",
+		"Start":    "08:15",
+		"Timeout":  "90m",
+	})
+
+	meeting := f.Get("Meeting").(time.Time)
+	if !meeting.Equal(time.Date(2013, 5, 17, 14, 30, 0, 0, time.UTC)) {
+		err(t, "incorrect Meeting", meeting, "2013-05-17T14:30")
+	}
+
+	if start := f.Times[f.Field("Start")]; start.Hour() != 8 || start.Minute() != 15 {
+		err(t, "incorrect Start", start, "08:15")
+	}
+
+	if f.Durations[f.Field("Timeout")] != 90*time.Minute {
+		err(t, "incorrect Timeout", f.Durations[f.Field("Timeout")], 90*time.Minute)
+	}
+
+	if len(f.FieldErrors[f.Field("Birthday")]) != 1 {
+		err(t, "Birthday before min should be an error", f.FieldErrors[f.Field("Birthday")], 1)
+	}
+
+	if len(f.FieldErrors[f.Field("Timeout")]) != 1 {
+		err(t, "Timeout above max should be an error", f.FieldErrors[f.Field("Timeout")], 1)
+	}
+}
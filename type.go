@@ -12,6 +12,13 @@ const (
 	Struct
 	Fill
 	Bool
+	File
+	FileArray
+	Date
+	Time
+	DateTime
+	Duration
+	Collection
 )
 
 type Type int
@@ -0,0 +1,221 @@
+package goform
+
+import (
+	"net/mail"
+	"net/url"
+	"reflect"
+	"regexp"
+	"unicode/utf8"
+)
+
+// Validator checks the value of a filled Field. The value is what FormHandler.Get
+// returns for the Field. Validators are run by FormHandler.Validate for every
+// filled Field and should return a *ValidationError for an invalid value.
+type Validator interface {
+	Validate(field *Field, value interface{}) error
+}
+
+// Validators adds the given Validators to the Field and sets the
+// corresponding html5 constraint attributes
+func Validators(field *Field, validators ...Validator) *Field {
+	field.Validators = append(field.Validators, validators...)
+	field.setConstraintInfos()
+	return field
+}
+
+func (ø *Field) runValidators(form *FormHandler) {
+	if len(ø.Validators) == 0 || !form.IsFilledField(ø) {
+		return
+	}
+	value := form.get(ø)
+	for _, v := range ø.Validators {
+		if err := v.Validate(ø, value); err != nil {
+			form.AddFieldError(ø, err)
+		}
+	}
+}
+
+// MinRule requires Int and Float values (or the elements of IntArray and FloatArray values) to be >= Min
+type MinRule struct{ Min float64 }
+
+// MaxRule requires Int and Float values (or the elements of IntArray and FloatArray values) to be <= Max
+type MaxRule struct{ Max float64 }
+
+// MinLengthRule requires String values (or the elements of StringArray values) to have at least Length characters
+type MinLengthRule struct{ Length int }
+
+// MaxLengthRule requires String values (or the elements of StringArray values) to have at most Length characters
+type MaxLengthRule struct{ Length int }
+
+// PatternRule requires String values (or the elements of StringArray values) to match Expr completely
+type PatternRule struct {
+	Expr   string
+	Regexp *regexp.Regexp // Expr anchored at the beginning and end
+}
+
+// MinItemsRule requires array values to have at least Count elements
+type MinItemsRule struct{ Count int }
+
+// MaxItemsRule requires array values to have at most Count elements
+type MaxItemsRule struct{ Count int }
+
+// EmailRule requires String values (or the elements of StringArray values) to be email addresses
+type EmailRule struct{}
+
+// URLRule requires String values (or the elements of StringArray values) to be absolute urls
+type URLRule struct{}
+
+// UUIDRule requires String values (or the elements of StringArray values) to be uuids
+type UUIDRule struct{}
+
+func Min(min float64) Validator    { return MinRule{min} }
+func Max(max float64) Validator    { return MaxRule{max} }
+func MinLength(l int) Validator    { return MinLengthRule{l} }
+func MaxLength(l int) Validator    { return MaxLengthRule{l} }
+func MinItems(count int) Validator { return MinItemsRule{count} }
+func MaxItems(count int) Validator { return MaxItemsRule{count} }
+func Email() Validator             { return EmailRule{} }
+func URL() Validator               { return URLRule{} }
+func UUID() Validator              { return UUIDRule{} }
+func Pattern(expr string) Validator {
+	return PatternRule{expr, regexp.MustCompile("^(?:" + expr + ")$")}
+}
+
+var uuidRegexp = regexp.MustCompile("^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$")
+
+func (ø MinRule) Validate(field *Field, value interface{}) error {
+	return eachNumber(value, func(n float64, bound func(float64) float64) error {
+		if n < bound(ø.Min) {
+			return newValidationError(field, CodeMin, value, Params{"min": ø.Min}, "%v is less than %v", n, ø.Min)
+		}
+		return nil
+	})
+}
+
+func (ø MaxRule) Validate(field *Field, value interface{}) error {
+	return eachNumber(value, func(n float64, bound func(float64) float64) error {
+		if n > bound(ø.Max) {
+			return newValidationError(field, CodeMax, value, Params{"max": ø.Max}, "%v is greater than %v", n, ø.Max)
+		}
+		return nil
+	})
+}
+
+func (ø MinLengthRule) Validate(field *Field, value interface{}) error {
+	return eachString(value, func(s string) error {
+		if utf8.RuneCountInString(s) < ø.Length {
+			return newValidationError(field, CodeTooShort, value, Params{"min_length": ø.Length}, "%#v is shorter than %d characters", s, ø.Length)
+		}
+		return nil
+	})
+}
+
+func (ø MaxLengthRule) Validate(field *Field, value interface{}) error {
+	return eachString(value, func(s string) error {
+		if utf8.RuneCountInString(s) > ø.Length {
+			return newValidationError(field, CodeTooLong, value, Params{"max_length": ø.Length}, "%#v is longer than %d characters", s, ø.Length)
+		}
+		return nil
+	})
+}
+
+func (ø PatternRule) Validate(field *Field, value interface{}) error {
+	return eachString(value, func(s string) error {
+		if !ø.Regexp.MatchString(s) {
+			return newValidationError(field, CodePattern, value, Params{"pattern": ø.Expr}, "%#v does not match %s", s, ø.Expr)
+		}
+		return nil
+	})
+}
+
+func (ø MinItemsRule) Validate(field *Field, value interface{}) error {
+	if n := itemCount(value); n < ø.Count {
+		return newValidationError(field, CodeTooFewItems, value, Params{"min_items": ø.Count}, "%d items are less than %d", n, ø.Count)
+	}
+	return nil
+}
+
+func (ø MaxItemsRule) Validate(field *Field, value interface{}) error {
+	if n := itemCount(value); n > ø.Count {
+		return newValidationError(field, CodeTooManyItems, value, Params{"max_items": ø.Count}, "%d items are more than %d", n, ø.Count)
+	}
+	return nil
+}
+
+func (ø EmailRule) Validate(field *Field, value interface{}) error {
+	return eachString(value, func(s string) error {
+		if addr, err := mail.ParseAddress(s); err != nil || addr.Address != s {
+			return newValidationError(field, CodeEmail, value, nil, "%#v is no email address", s)
+		}
+		return nil
+	})
+}
+
+func (ø URLRule) Validate(field *Field, value interface{}) error {
+	return eachString(value, func(s string) error {
+		if u, err := url.Parse(s); err != nil || u.Scheme == "" || u.Host == "" {
+			return newValidationError(field, CodeURL, value, nil, "%#v is no url", s)
+		}
+		return nil
+	})
+}
+
+func (ø UUIDRule) Validate(field *Field, value interface{}) error {
+	return eachString(value, func(s string) error {
+		if !uuidRegexp.MatchString(s) {
+			return newValidationError(field, CodeUUID, value, nil, "%#v is no uuid", s)
+		}
+		return nil
+	})
+}
+
+// calls fn for a number or each number of an array, stops at the first error.
+// bound converts a limit to the precision of the number, so that float32 values
+// are compared in float32 precision (float64(float32(1.8)) is less than 1.8).
+func eachNumber(value interface{}, fn func(n float64, bound func(float64) float64) error) error {
+	switch v := value.(type) {
+	case int:
+		return fn(float64(v), exact)
+	case float32:
+		return fn(float64(v), toFloat32)
+	case []int:
+		for _, n := range v {
+			if err := fn(float64(n), exact); err != nil {
+				return err
+			}
+		}
+	case []float32:
+		for _, n := range v {
+			if err := fn(float64(n), toFloat32); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func exact(f float64) float64     { return f }
+func toFloat32(f float64) float64 { return float64(float32(f)) }
+
+// calls fn for a string or each string of an array, stops at the first error
+func eachString(value interface{}, fn func(string) error) error {
+	switch v := value.(type) {
+	case string:
+		return fn(v)
+	case []string:
+		for _, s := range v {
+			if err := fn(s); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func itemCount(value interface{}) int {
+	rv := reflect.ValueOf(value)
+	if rv.Kind() == reflect.Slice {
+		return rv.Len()
+	}
+	return 1
+}
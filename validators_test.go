@@ -0,0 +1,133 @@
+package goform
+
+import (
+	. "github.com/metakeule/goh4/tag"
+	"testing"
+)
+
+func TestValidators(t *testing.T) {
+	f := NewForm(
+		Validators(Optional("Age", Int, INPUT()), Min(18), Max(150)),
+		Validators(Optional("Height", Float, INPUT()), Max(2.5)),
+		Validators(Optional("Name", String, INPUT()), MinLength(2), MaxLength(5)),
+		Validators(Optional("Zip", String, INPUT()), Pattern("[0-9]{5}")),
+		Validators(Optional("Tags", StringArray, INPUT()), MinItems(1), MaxItems(2)),
+		Validators(Optional("Mail", String, INPUT()), Email()),
+		Validators(Optional("Homepage", String, INPUT()), URL()),
+		Validators(Optional("Id", String, INPUT()), UUID()),
+		Validators(Optional("Unfilled", String, INPUT()), MinLength(3)),
+	)
+
+	_ = f.Parse(map[string]string{
+		"Age":      "12",
+		"Height":   "1.8",
+		"Name":     "Donald",
+		"Zip":      "123456",
+		"Tags":     "a,b,c",
+		"Mail":     "donald@example.com",
+		"Homepage": "example.com",
+		"Id":       "0d7e1c52-9a9c-4e0b-8e0f-6d2a1f0b3c4d",
+	})
+
+	codes := map[string]string{
+		"Age":      "min",
+		"Height":   "",
+		"Name":     "too_long",
+		"Zip":      "pattern",
+		"Tags":     "too_many_items",
+		"Mail":     "",
+		"Homepage": "url",
+		"Id":       "",
+		"Unfilled": "",
+	}
+
+	for name, code := range codes {
+		errs := f.FieldErrors[f.Field(name)]
+		if code == "" {
+			if len(errs) != 0 {
+				err(t, "unexpected errors for "+name, errs, "none")
+			}
+			continue
+		}
+		if len(errs) != 1 {
+			err(t, "incorrect number of errors for "+name, errs, 1)
+			continue
+		}
+		if ve, ok := errs[0].(*ValidationError); !ok || ve.Code != code || ve.Field != name {
+			err(t, "incorrect error for "+name, errs[0], code)
+		}
+	}
+}
+
+func TestValidatorsFloatBounds(t *testing.T) {
+	f := NewForm(
+		Validators(Optional("Height", Float, INPUT()), Min(1.8), Max(2.1)),
+		Validators(Optional("Share", Float, INPUT()), Max(0.1)),
+		Validators(Optional("Weights", FloatArray, INPUT()), Min(0.1), Max(1.8)),
+	)
+
+	if e := f.ParseFormValues(map[string][]string{"Height": {"1.8"}, "Share": {"0.1"}, "Weights": {"0.1", "1.8"}}); e != nil {
+		err(t, "values on the limits should be valid", e, nil)
+	}
+
+	f = NewForm(Validators(Optional("Height", Float, INPUT()), Min(1.8), Max(2.1)))
+	for _, v := range []string{"1.79", "2.11"} {
+		f.Reset()
+		if e := f.Parse(map[string]string{"Height": v}); e == nil {
+			err(t, v+" should be out of the limits", e, "error")
+		}
+	}
+}
+
+func TestConstraintAttributes(t *testing.T) {
+	f := NewForm(
+		Validators(Optional("Age", Int, INPUT()), Min(18), Max(150)),
+		Validators(Optional("Height", Float, INPUT()), Max(2.5)),
+		Validators(Optional("Name", String, INPUT()), MinLength(2), MaxLength(5)),
+		Validators(Optional("Zip", String, INPUT()), Pattern("[0-9]{5}")),
+		Validators(Optional("Mail", String, INPUT()), Email()),
+		Validators(Optional("Mails", StringArray, INPUT()), Email()),
+		Validators(Optional("Homepage", String, INPUT()), URL()),
+	)
+
+	attr := func(name string, key string) string {
+		return f.Field(name).Element.Fields()[0].Attribute(key)
+	}
+
+	for _, c := range [][3]string{
+		{"Age", "type", "number"},
+		{"Age", "min", "18"},
+		{"Age", "max", "150"},
+		{"Age", "step", "1"},
+		{"Height", "max", "2.5"},
+		{"Height", "step", "any"},
+		{"Name", "minlength", "2"},
+		{"Name", "maxlength", "5"},
+		{"Zip", "pattern", "[0-9]{5}"},
+		{"Mail", "type", "email"},
+		{"Mails", "multiple", "multiple"},
+		{"Homepage", "type", "url"},
+	} {
+		if is := attr(c[0], c[1]); is != c[2] {
+			err(t, "incorrect attribute "+c[1]+" of "+c[0], is, c[2])
+		}
+	}
+}
+
+func TestMultipleEmails(t *testing.T) {
+	f := NewForm(Validators(Optional("Mails", StringArray, INPUT()), Email(), MaxItems(2)))
+
+	if e := f.ParseFormValues(map[string][]string{"Mails": {"donald@duck.de, daisy@duck.de"}}); e != nil {
+		err(t, "the comma separated emails should be valid", e, nil)
+	}
+
+	mails := f.StringArrays[f.Field("Mails")]
+	if len(mails) != 2 || mails[1] != "daisy@duck.de" {
+		err(t, "the emails should be split", mails, []string{"donald@duck.de", "daisy@duck.de"})
+	}
+
+	f.Reset()
+	if e := f.ParseFormValues(map[string][]string{"Mails": {"a@duck.de,b@duck.de,c@duck.de"}}); e == nil {
+		err(t, "each email should be counted", e, "too_many_items")
+	}
+}
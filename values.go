@@ -0,0 +1,219 @@
+package goform
+
+import (
+	"encoding/json"
+	"fmt"
+	h "github.com/metakeule/goh4"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SetValues pre-fills the html of the Fields with the given values, keyed by Field name.
+// Values are formatted according to the Type of the Field, see Field.SetValue.
+// Numbers and dates are formatted for the Locale of the form or the Field.
+// Unknown names are ignored.
+func (ø *FormHandler) SetValues(vals map[string]interface{}) {
+	for name, v := range vals {
+		if field := ø.Fields[name]; field != nil {
+			field.setValue(v, field.locale(ø))
+		}
+	}
+}
+
+// SetValuesFrom pre-fills the html of the Fields with the exported fields of
+// the struct v (or the struct v points to). Struct fields are matched to
+// Fields like in Bind.
+func (ø *FormHandler) SetValuesFrom(v interface{}) {
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			return
+		}
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		panic(fmt.Sprintf("can't set values from %T: need a struct", v))
+	}
+	vals := map[string]interface{}{}
+	structValues(rv, vals)
+	ø.SetValues(vals)
+}
+
+func structValues(v reflect.Value, vals map[string]interface{}) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if sf.Anonymous && sf.Type.Kind() == reflect.Struct {
+			structValues(v.Field(i), vals)
+			continue
+		}
+		if sf.PkgPath != "" {
+			continue
+		}
+		if name := fieldName(sf); name != "-" {
+			vals[name] = v.Field(i).Interface()
+		}
+	}
+}
+
+// SetValue pre-fills the html of the Field with the given value:
+// the value attribute of inputs, the content of textareas, the selected
+// options of selects and the checked state of checkboxes and radios.
+// Struct, Map and Fill values are rendered as json, Collection values (slices of
+// maps or structs) as one entry per element, File values are ignored
+// since browsers don't allow to pre-fill file inputs. A nil value clears the Field.
+// Numbers and dates are formatted for the Locale of the Field.
+func (ø *Field) SetValue(v interface{}) {
+	ø.setValue(v, ø.Locale)
+}
+
+func (ø *Field) setValue(v interface{}, locale string) {
+	if ø.Type == File || ø.Type == FileArray {
+		return
+	}
+	if ø.Type == Collection {
+		ø.setEntryValues(v, locale)
+		return
+	}
+	ø.setElementValues(ø.formatValue(v, locale), locale)
+}
+
+// FormatValue returns the string representation of the given value as it
+// would be submitted for the Field (one string per array element)
+func (ø *Field) FormatValue(v interface{}) (strs []string) {
+	return ø.formatValue(v, ø.Locale)
+}
+
+func (ø *Field) formatValue(v interface{}, locale string) (strs []string) {
+	strs = []string{}
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Ptr || rv.Kind() == reflect.Interface {
+		if rv.IsNil() {
+			return
+		}
+		rv = rv.Elem()
+	}
+	if !rv.IsValid() {
+		return
+	}
+
+	switch ø.Type {
+	case Struct, Map, Fill:
+		if s, ok := rv.Interface().(string); ok {
+			return []string{s}
+		}
+		b, err := json.Marshal(v)
+		if err != nil {
+			panic(fmt.Sprintf("can't set value of field %s: %s", ø.Name, err))
+		}
+		return []string{string(b)}
+	case IntArray, FloatArray, StringArray:
+		if rv.Kind() == reflect.Slice || rv.Kind() == reflect.Array {
+			for i := 0; i < rv.Len(); i++ {
+				strs = append(strs, ø.formatScalar(rv.Index(i).Interface(), locale))
+			}
+			return
+		}
+	}
+	return []string{ø.formatScalar(rv.Interface(), locale)}
+}
+
+func (ø *Field) formatScalar(v interface{}, locale string) string {
+	switch val := v.(type) {
+	case string:
+		return val
+	case float32:
+		return ø.formatFloat(float64(val), 32, locale)
+	case float64:
+		return ø.formatFloat(val, 64, locale)
+	case bool:
+		return strconv.FormatBool(val)
+	case time.Time:
+		return ø.formatTime(val, locale)
+	case time.Duration:
+		return val.String()
+	}
+	return fmt.Sprint(v)
+}
+
+// writes the given strings into the form elements of the Field. Several strings
+// for one input or textarea are joined like FlatValues splits them for the locale.
+func (ø *Field) setElementValues(strs []string, locale string) {
+	sep := ø.arraySeparator(locale)
+	inputs := []*h.Element{}
+	for _, el := range ø.Element.Fields() {
+		switch el.Tag() {
+		case "select":
+			setSelected(el, strs)
+		case "textarea":
+			el.SetContent(h.Text(strings.Join(strs, sep)))
+		default:
+			switch el.Attribute("type") {
+			case "checkbox", "radio":
+				setChecked(el, strs)
+			default:
+				inputs = append(inputs, el)
+			}
+		}
+	}
+
+	// several inputs for one Field get one value each
+	if len(inputs) > 1 {
+		for i, el := range inputs {
+			if i < len(strs) {
+				el.Add(h.Attr("value", strs[i]))
+			} else {
+				el.RemoveAttribute("value")
+			}
+		}
+		return
+	}
+	for _, el := range inputs {
+		if len(strs) == 0 {
+			el.RemoveAttribute("value")
+			continue
+		}
+		el.Add(h.Attr("value", strings.Join(strs, sep)))
+	}
+}
+
+func setSelected(sel *h.Element, strs []string) {
+	for _, opt := range sel.All(h.Tag("option")) {
+		val, hasVal := opt.Attributes()["value"]
+		if !hasVal {
+			val = opt.InnerHtml()
+		}
+		if hasString(strs, val) {
+			opt.Add(h.Attr("selected", "selected"))
+		} else {
+			opt.RemoveAttribute("selected")
+		}
+	}
+}
+
+func setChecked(input *h.Element, strs []string) {
+	val, hasVal := input.Attributes()["value"]
+	checked := false
+	if hasVal {
+		checked = hasString(strs, val)
+	} else {
+		// checkboxes without value submit "on"
+		checked = len(strs) > 0 && (strs[0] == "true" || strs[0] == "on")
+	}
+	if checked {
+		input.Add(h.Attr("checked", "checked"))
+	} else {
+		input.RemoveAttribute("checked")
+	}
+}
+
+func hasString(a []string, s string) bool {
+	for _, e := range a {
+		if e == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,81 @@
+package goform
+
+import (
+	h "github.com/metakeule/goh4"
+	. "github.com/metakeule/goh4/tag"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSetValues(t *testing.T) {
+	f := NewForm(
+		Optional("Name", String, INPUT()),
+		Optional("Bio", String, TEXTAREA()),
+		Selection(Optional("Age", Int, SELECT(OPTION("eighteen"), OPTION("twentyone"))), 18, 21),
+		Optional("Admin", Bool, INPUT(h.Attr("type", "checkbox"))),
+		Optional("Color", String,
+			INPUT(h.Attr("type", "radio", "value", "red")),
+			INPUT(h.Attr("type", "radio", "value", "blue"))),
+		Optional("Height", Float, INPUT()),
+		Optional("Tags", StringArray, INPUT()),
+		Optional("Born", Date, INPUT()),
+		Optional("Address", Constructor(func() interface{} { return &Address{} }), TEXTAREA()),
+	)
+
+	f.SetValues(map[string]interface{}{
+		"Name":    "Donald",
+		"Bio":     "<b>duck</b>",
+		"Age":     21,
+		"Admin":   true,
+		"Color":   "blue",
+		"Height":  float32(1.82),
+		"Tags":    []string{"a", "b"},
+		"Born":    time.Date(1934, 6, 9, 0, 0, 0, 0, time.UTC),
+		"Address": &Address{City: "Entenhausen"},
+		"Unknown": "ignored",
+	})
+
+	attr := func(name string, i int, key string) string {
+		return f.Field(name).Element.Fields()[i].Attribute(key)
+	}
+
+	for _, c := range [][3]string{
+		{attr("Name", 0, "value"), "Donald"},
+		{attr("Admin", 0, "checked"), "checked"},
+		{attr("Color", 0, "checked"), ""},
+		{attr("Color", 1, "checked"), "checked"},
+		{attr("Height", 0, "value"), "1.82"},
+		{attr("Tags", 0, "value"), "a,b"},
+		{attr("Born", 0, "value"), "1934-06-09"},
+	} {
+		if c[0] != c[1] {
+			err(t, "incorrect attribute", c[0], c[1])
+		}
+	}
+
+	if bio := f.Field("Bio").Element.Fields()[0].InnerHtml(); bio != "&lt;b&gt;duck&lt;/b&gt;" {
+		err(t, "incorrect content of Bio", bio, "&lt;b&gt;duck&lt;/b&gt;")
+	}
+
+	if opt := f.Field("Age").Element.Any(h.Attr("value", "21")); opt == nil || opt.Attribute("selected") != "selected" {
+		err(t, "option 21 of Age should be selected", opt, "selected")
+	}
+
+	if addr := f.Field("Address").Element.Fields()[0].InnerHtml(); !strings.Contains(addr, "Entenhausen") {
+		err(t, "Address should contain json", addr, "Entenhausen")
+	}
+}
+
+func TestSetValuesFrom(t *testing.T) {
+	f := NewFormFromStruct(structFormPerson{})
+	f.SetValuesFrom(&structFormPerson{Name: "Donald", Age: 65})
+
+	if v := f.Field("name").Element.Fields()[0].Attribute("value"); v != "Donald" {
+		err(t, "incorrect value of name", v, "Donald")
+	}
+
+	if opt := f.Field("age").Element.Any(h.Attr("value", "65")); opt == nil || opt.Attribute("selected") != "selected" {
+		err(t, "option 65 of age should be selected", opt, "selected")
+	}
+}
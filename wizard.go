@@ -0,0 +1,376 @@
+package goform
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	h "github.com/metakeule/goh4"
+	. "github.com/metakeule/goh4/tag"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// the names of the inputs that a Wizard adds to each step
+const (
+	WizardStateName = "_wizard" // the hidden input with the encoded WizardState
+	WizardBackName  = "_back"   // the button to go back to the previous step
+)
+
+// the keys of the current step and the session in the state of a Wizard
+const (
+	wizardStepKey    = "_step"
+	wizardSessionKey = "_session"
+)
+
+// the defaults for SignedState and MemoryState
+const (
+	DefaultWizardStateTTL  = 24 * time.Hour // the lifetime of a state
+	DefaultMaxWizardStates = 10000
+)
+
+// ErrInvalidWizardState is added to the GeneralValidationErrors of a Wizard if the
+// state of the submitted step is missing, forged or expired. The Wizard then
+// starts again with the first step.
+var ErrInvalidWizardState = errors.New("the form has expired, please start again")
+
+// ErrNoWizardSecret is returned by SignedState if it has no Secret
+var ErrNoWizardSecret = errors.New("SignedState needs a Secret")
+
+// WizardState carries the state of a Wizard (the current step and the raw values
+// of the answered steps) between the requests, via the hidden input WizardStateName.
+type WizardState interface {
+	// Encode returns the value of the hidden input for the state
+	Encode(state url.Values) (string, error)
+	// Decode returns the state for the value of the hidden input
+	Decode(value string) (url.Values, error)
+}
+
+// SignedState is a WizardState that keeps the state in the hidden input, signed with
+// HMAC-SHA256 so that it can't be changed by the client. The signature includes the
+// time the state was issued, so that it expires after MaxAge. Within that time it can
+// be submitted again, e.g. by the browsers back button; set the Session of the Wizard
+// to prevent other clients from submitting it. Since the client can read the state,
+// it should not be used for secret values.
+type SignedState struct {
+	Secret []byte        // the key of the signature, must not be empty
+	MaxAge time.Duration // 0 means DefaultWizardStateTTL
+}
+
+func (ø SignedState) Encode(state url.Values) (string, error) {
+	if len(ø.Secret) == 0 {
+		return "", ErrNoWizardSecret
+	}
+	issued := strconv.FormatInt(time.Now().Unix(), 10)
+	payload := issued + "." + base64.RawURLEncoding.EncodeToString([]byte(state.Encode()))
+	return payload + "." + ø.sign(payload), nil
+}
+
+func (ø SignedState) Decode(value string) (url.Values, error) {
+	if len(ø.Secret) == 0 {
+		return nil, ErrNoWizardSecret
+	}
+	i := strings.LastIndex(value, ".")
+	if i < 0 || !hmac.Equal([]byte(value[i+1:]), []byte(ø.sign(value[:i]))) {
+		return nil, ErrInvalidWizardState
+	}
+	parts := strings.SplitN(value[:i], ".", 2)
+	if len(parts) != 2 || ø.expired(parts[0]) {
+		return nil, ErrInvalidWizardState
+	}
+	b, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, ErrInvalidWizardState
+	}
+	return url.ParseQuery(string(b))
+}
+
+// reports if the state issued at the given unix time is too old
+func (ø SignedState) expired(issued string) bool {
+	sec, err := strconv.ParseInt(issued, 10, 64)
+	if err != nil {
+		return true
+	}
+	maxAge := ø.MaxAge
+	if maxAge <= 0 {
+		maxAge = DefaultWizardStateTTL
+	}
+	age := time.Since(time.Unix(sec, 0))
+	// allow for clocks of several servers that are slightly apart
+	return age > maxAge || age < -time.Minute
+}
+
+func (ø SignedState) sign(payload string) string {
+	mac := hmac.New(sha256.New, ø.Secret)
+	mac.Write([]byte(payload))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// MemoryState is a WizardState that keeps the states in memory, the hidden input
+// only holds a random key. Each key can be decoded once, so the browsers back button
+// leads to an ErrInvalidWizardState. States expire after the TTL and if there are
+// more than MaxStates, the oldest ones are removed.
+// It is safe for concurrent use.
+type MemoryState struct {
+	TTL       time.Duration // how long a state is kept, 0 means DefaultWizardStateTTL
+	MaxStates int           // the maximal number of kept states, 0 means DefaultMaxWizardStates
+	mu        sync.Mutex
+	states    map[string]memoryState
+	keys      []string // the keys of the states in the order they were stored
+}
+
+type memoryState struct {
+	values  url.Values
+	expires time.Time
+}
+
+func NewMemoryState() *MemoryState {
+	return &MemoryState{}
+}
+
+func (ø *MemoryState) Encode(state url.Values) (string, error) {
+	rnd := make([]byte, 16)
+	if _, err := rand.Read(rnd); err != nil {
+		return "", err
+	}
+	key := hex.EncodeToString(rnd)
+	ttl := ø.TTL
+	if ttl <= 0 {
+		ttl = DefaultWizardStateTTL
+	}
+	now := time.Now()
+	ø.mu.Lock()
+	defer ø.mu.Unlock()
+	if ø.states == nil {
+		ø.states = map[string]memoryState{}
+	}
+	ø.removeOld(now)
+	ø.states[key] = memoryState{values: state, expires: now.Add(ttl)}
+	ø.keys = append(ø.keys, key)
+	return key, nil
+}
+
+// removes the oldest states while they are expired or there are too many.
+// The keys of decoded states are removed when they become the oldest.
+func (ø *MemoryState) removeOld(now time.Time) {
+	max := ø.MaxStates
+	if max <= 0 {
+		max = DefaultMaxWizardStates
+	}
+	for len(ø.keys) > 0 {
+		s, ok := ø.states[ø.keys[0]]
+		if ok && len(ø.keys) < max && now.Before(s.expires) {
+			return
+		}
+		delete(ø.states, ø.keys[0])
+		ø.keys = ø.keys[1:]
+	}
+}
+
+func (ø *MemoryState) Decode(value string) (url.Values, error) {
+	ø.mu.Lock()
+	defer ø.mu.Unlock()
+	state, ok := ø.states[value]
+	if !ok {
+		return nil, ErrInvalidWizardState
+	}
+	delete(ø.states, value)
+	if !time.Now().Before(state.expires) {
+		return nil, ErrInvalidWizardState
+	}
+	return state.values, nil
+}
+
+// Wizard splits a form across several pages, one step for each Group.
+// The Element of the FormHandler shows the Current step only.
+// A submitted step is validated on its own (required check, Selection, Validators etc.)
+// and the answers are carried forward with the State. All hooks (BeforeParsing
+// included), the Validation and the Action of the FormHandler run only once, with
+// all answers after the last step.
+// File Fields should be part of the last step, since uploads are not carried forward.
+// Like a FormHandler a Wizard must not be shared between requests.
+type Wizard struct {
+	*FormHandler
+	Steps      []*Group
+	State      WizardState
+	Session    string // identifies the client, e.g. by the session id. If set, only states of the same Session are accepted
+	Current    int    // the index of the current step
+	Done       bool   // if the last step has been submitted successfully and the Action was run
+	BackText   string // the text of the back button, "" means "Back"
+	NextText   string // the text of the submit button of all steps but the last, "" means "Next"
+	FinishText string // the text of the submit button of the last step, "" means "Finish"
+	answers    url.Values
+}
+
+// NewWizard creates a Wizard for the given steps
+func NewWizard(state WizardState, steps ...*Group) (ø *Wizard) {
+	objects := []interface{}{}
+	for _, step := range steps {
+		objects = append(objects, step)
+	}
+	ø = &Wizard{FormHandler: NewForm(objects...), Steps: steps, State: state, answers: url.Values{}}
+	ø.showStep()
+	return
+}
+
+// ParseRequest parses the submitted step of the request (urlencoded or multipart).
+// If the back button was pressed, the step before is shown without validating the
+// submitted one. Otherwise the step is validated and the next step is shown if it
+// is valid. After the last step the answers of all steps are run through the
+// pipeline of FormHandler.ParseRequest, an invalid earlier step is then shown again.
+// A request without a valid CSRF token (if the form has a CSRF store) shows the
+// first step again.
+func (ø *Wizard) ParseRequest(r *http.Request) (err error) {
+	err = ø.parseStep(r)
+	ø.showStep()
+	if err == nil {
+		// the state of the shown step could not be encoded
+		err = ø.formErrors()
+	}
+	return
+}
+
+// parses the submitted step and sets the step to show
+func (ø *Wizard) parseStep(r *http.Request) (err error) {
+	ø.prepareRequest(r)
+	if err = ø.readForm(r); err != nil {
+		return
+	}
+	if err = ø.verifyCSRF(r); err != nil {
+		return
+	}
+	ø.resetParsed()
+
+	state, decodeErr := ø.State.Decode(r.Form.Get(WizardStateName))
+	step, convErr := strconv.Atoi(state.Get(wizardStepKey))
+	if decodeErr != nil || convErr != nil || step < 0 || step >= len(ø.Steps) || state.Get(wizardSessionKey) != ø.Session {
+		ø.Current, ø.answers = 0, url.Values{}
+		ø.AddValidationError(ErrInvalidWizardState)
+		return ø.formErrors()
+	}
+	state.Del(wizardStepKey)
+	state.Del(wizardSessionKey)
+	ø.Current, ø.answers = step, state
+
+	for _, field := range fieldsOf(ø.Steps[step].Order) {
+		if field.Type == Collection {
+			// the entries are submitted by path, e.g. "Addresses[0].City"
+			prefix := field.Name + "["
+			for k := range ø.answers {
+				if strings.HasPrefix(k, prefix) {
+					ø.answers.Del(k)
+				}
+			}
+			for k, vals := range r.Form {
+				if strings.HasPrefix(k, prefix) {
+					ø.answers[k] = vals
+				}
+			}
+			continue
+		}
+		if vals, ok := r.Form[field.Name]; ok {
+			ø.answers[field.Name] = vals
+		} else {
+			ø.answers.Del(field.Name)
+		}
+	}
+
+	if r.Form.Get(WizardBackName) != "" && step > 0 {
+		ø.Current--
+		return nil
+	}
+
+	last := step == len(ø.Steps)-1
+	if last && ø.BeforeParsing != nil {
+		ø.BeforeParsing(ø.FormHandler)
+	}
+	ø.parseSource(URLValues(ø.answers))
+	if r.MultipartForm != nil {
+		ø.parseFiles(r.MultipartForm.File)
+	}
+
+	if last {
+		err = ø.afterParsing()
+		ø.Done = err == nil
+		ø.Current = ø.firstInvalidStep()
+		return
+	}
+
+	for _, field := range fieldsOf(ø.Steps[step].Order) {
+		if field.Required && ø.IsNil(field) {
+			ø.AddFieldError(field, newValidationError(field, CodeRequired, nil, nil, "required"))
+		}
+		ø.checkField(field)
+	}
+	if err = ø.formErrors(); err == nil {
+		ø.Current++
+	}
+	return
+}
+
+// the index of the first step with Field errors, or of the current step if there are none
+func (ø *Wizard) firstInvalidStep() int {
+	for i, step := range ø.Steps {
+		for _, field := range fieldsOf(step.Order) {
+			if len(ø.FieldErrors[field]) > 0 {
+				return i
+			}
+		}
+	}
+	return ø.Current
+}
+
+// sets the Element of the FormHandler to the current step with its answers,
+// the hidden state and the buttons. If the state can't be encoded, the error
+// is added to the GeneralValidationErrors, since the step can't be submitted.
+func (ø *Wizard) showStep() {
+	step := ø.Steps[ø.Current]
+	for _, field := range fieldsOf(step.Order) {
+		if vals, ok := ø.answers[field.Name]; ok && field.Type != File && field.Type != FileArray {
+			field.setElementValues(vals, field.locale(ø.FormHandler))
+		}
+	}
+
+	ø.Element = FORM(ATTR("method", "POST", "enctype", "multipart/form-data"), step.Element)
+	ø.errorSummary = nil
+
+	state := url.Values{}
+	for k, v := range ø.answers {
+		state[k] = v
+	}
+	state.Set(wizardStepKey, strconv.Itoa(ø.Current))
+	if ø.Session != "" {
+		state.Set(wizardSessionKey, ø.Session)
+	}
+	if value, err := ø.State.Encode(state); err == nil {
+		ø.Element.Add(INPUT(h.Attr("type", "hidden", "name", WizardStateName, "value", value)))
+	} else {
+		ø.AddValidationError(err)
+	}
+
+	// the submit button comes first, so that it is the default button when pressing enter
+	text := textOr(ø.NextText, "Next")
+	if ø.Current == len(ø.Steps)-1 {
+		text = textOr(ø.FinishText, "Finish")
+	}
+	ø.AddSubmitButton(text)
+
+	if ø.Current > 0 {
+		back := BUTTON(h.Attr("type", "submit", "name", WizardBackName, "value", "1", "formnovalidate", "formnovalidate"), h.Text(textOr(ø.BackText, "Back")))
+		back.AddClass(ø.theme().Button()...)
+		ø.Element.Add(back)
+	}
+}
+
+func textOr(text string, def string) string {
+	if text == "" {
+		return def
+	}
+	return text
+}
@@ -0,0 +1,250 @@
+package goform
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	h "github.com/metakeule/goh4"
+	. "github.com/metakeule/goh4/tag"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newTestWizard(state WizardState, saved *map[string]interface{}) *Wizard {
+	w := NewWizard(state,
+		Fieldset("person", "Person", Required("Name", String, INPUT())),
+		Fieldset("contact", "Contact", Validators(Required("Email", String, INPUT()), Email())),
+		Fieldset("confirm", "Confirm", Optional("Newsletter", Bool, INPUT(h.Attr("type", "checkbox")))),
+	)
+	w.Action = func(f *FormHandler) error {
+		*saved = f.Map()
+		return nil
+	}
+	return w
+}
+
+// submits the current step of the wizard with the given values
+func submitStep(w *Wizard, vals url.Values) error {
+	vals.Set(WizardStateName, w.Any(h.Attr("name", WizardStateName)).Attribute("value"))
+	r := httptest.NewRequest("POST", "/", strings.NewReader(vals.Encode()))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	return w.ParseRequest(r)
+}
+
+// starts a new request of the wizard at the state of the old one, like a new page
+func nextRequest(w *Wizard, saved *map[string]interface{}) *Wizard {
+	n := newTestWizard(w.State, saved)
+	n.Element = w.Element
+	return n
+}
+
+func TestWizard(t *testing.T) {
+	var saved map[string]interface{}
+	for _, state := range []WizardState{SignedState{Secret: []byte("secret")}, NewMemoryState()} {
+		saved = nil
+		w := newTestWizard(state, &saved)
+
+		if w.Any(h.Id("group-person")) == nil || w.Any(h.Id("group-contact")) != nil {
+			err(t, "only the first step should be shown", w.String(), "person")
+		}
+
+		if e := submitStep(w, url.Values{}); e == nil || w.Current != 0 {
+			err(t, "a missing name should be an error of the first step", w.Current, 0)
+		}
+
+		w = nextRequest(w, &saved)
+		if e := submitStep(w, url.Values{"Name": {"Donald"}, "Email": {"invalid"}}); e != nil || w.Current != 1 {
+			err(t, "only the first step should be validated", e, nil)
+		}
+
+		w = nextRequest(w, &saved)
+		if e := submitStep(w, url.Values{"Email": {"donald@duck.de"}, WizardBackName: {"1"}}); e != nil || w.Current != 0 {
+			err(t, "back should show the first step", w.Current, 0)
+		}
+
+		if w.Field("Name").Element.Fields()[0].Attribute("value") != "Donald" {
+			err(t, "the answers should be shown again", w.String(), "Donald")
+		}
+
+		w = nextRequest(w, &saved)
+		submitStep(w, url.Values{"Name": {"Daisy"}})
+		w = nextRequest(w, &saved)
+		if e := submitStep(w, url.Values{"Email": {"daisy@duck.de"}}); e != nil || w.Current != 2 {
+			err(t, "second step should be valid", e, nil)
+		}
+
+		if saved != nil {
+			err(t, "the action should not run before the last step", saved, nil)
+		}
+
+		w = nextRequest(w, &saved)
+		if e := submitStep(w, url.Values{"Newsletter": {"on"}}); e != nil || !w.Done {
+			err(t, "last step should be valid", e, nil)
+		}
+
+		if saved["Name"] != "Daisy" || saved["Email"] != "daisy@duck.de" || saved["Newsletter"] != true {
+			err(t, "the action should get the answers of all steps", saved, "Daisy, daisy@duck.de, true")
+		}
+	}
+}
+
+func TestWizardInvalidState(t *testing.T) {
+	var saved map[string]interface{}
+	w := newTestWizard(SignedState{Secret: []byte("secret")}, &saved)
+
+	// a state for the last step, signed with the wrong secret
+	forged, _ := SignedState{Secret: []byte("other")}.Encode(url.Values{wizardStepKey: {"2"}})
+	w.Any(h.Attr("name", WizardStateName)).Add(h.Attr("value", forged))
+
+	if e := submitStep(w, url.Values{"Newsletter": {"on"}}); !errors.Is(e, ErrGeneralValidationErrors) || w.Current != 0 || saved != nil {
+		err(t, "a forged state should start again", w.Current, 0)
+	}
+}
+
+func TestSignedStateNeedsSecret(t *testing.T) {
+	if _, e := (SignedState{}).Encode(url.Values{wizardStepKey: {"2"}}); e != ErrNoWizardSecret {
+		err(t, "a state without Secret should not be encoded", e, ErrNoWizardSecret)
+	}
+
+	// a state signed with an empty key
+	payload := strconv.FormatInt(time.Now().Unix(), 10) + "." + base64.RawURLEncoding.EncodeToString([]byte(wizardStepKey+"=2"))
+	mac := hmac.New(sha256.New, nil)
+	mac.Write([]byte(payload))
+	forged := payload + "." + base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+	if _, e := (SignedState{}).Decode(forged); e != ErrNoWizardSecret {
+		err(t, "a state forged with an empty key should be refused", e, ErrNoWizardSecret)
+	}
+
+	var saved map[string]interface{}
+	w := newTestWizard(SignedState{}, &saved)
+	r := httptest.NewRequest("POST", "/", strings.NewReader(url.Values{WizardStateName: {forged}, "Newsletter": {"on"}}.Encode()))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	if e := w.ParseRequest(r); e == nil || w.Current != 0 || saved != nil {
+		err(t, "a wizard without Secret should not accept a forged state", w.Current, 0)
+	}
+}
+
+func TestMemoryStateLimits(t *testing.T) {
+	state := &MemoryState{MaxStates: 2}
+	first, _ := state.Encode(url.Values{"a": {"1"}})
+	second, _ := state.Encode(url.Values{"b": {"2"}})
+	state.Encode(url.Values{"c": {"3"}})
+
+	if len(state.states) != 2 {
+		err(t, "incorrect number of states", len(state.states), 2)
+	}
+
+	if _, e := state.Decode(first); e == nil {
+		err(t, "the oldest state should be removed", e, ErrInvalidWizardState)
+	}
+
+	if vals, e := state.Decode(second); e != nil || vals.Get("b") != "2" {
+		err(t, "the newer state should be kept", vals, "b=2")
+	}
+
+	state = &MemoryState{TTL: time.Nanosecond}
+	key, _ := state.Encode(url.Values{"a": {"1"}})
+	time.Sleep(time.Millisecond)
+	if _, e := state.Decode(key); e == nil {
+		err(t, "an expired state should be invalid", e, ErrInvalidWizardState)
+	}
+
+	state.Encode(url.Values{"b": {"2"}})
+	if len(state.states) != 1 || len(state.keys) != 1 {
+		err(t, "expired states should be removed", len(state.states), 1)
+	}
+}
+
+type failingState struct{ SignedState }
+
+func (failingState) Encode(url.Values) (string, error) { return "", errors.New("can't encode") }
+
+func TestWizardStateEncodeError(t *testing.T) {
+	var saved map[string]interface{}
+	w := newTestWizard(failingState{}, &saved)
+
+	if len(w.GeneralValidationErrors) != 1 || w.Any(h.Attr("name", WizardStateName)) != nil {
+		err(t, "the encode error should be recorded", w.GeneralValidationErrors, "can't encode")
+	}
+}
+
+func TestSignedStateExpires(t *testing.T) {
+	state := SignedState{Secret: []byte("secret"), MaxAge: time.Nanosecond}
+	value, _ := state.Encode(url.Values{wizardStepKey: {"1"}})
+	time.Sleep(time.Millisecond)
+	if _, e := state.Decode(value); e == nil {
+		err(t, "an expired state should be invalid", e, ErrInvalidWizardState)
+	}
+
+	state.MaxAge = 0
+	value, _ = state.Encode(url.Values{wizardStepKey: {"1"}})
+	if vals, e := state.Decode(value); e != nil || vals.Get(wizardStepKey) != "1" {
+		err(t, "a fresh state should be valid", e, nil)
+	}
+
+	// changing the time the state was issued breaks the signature
+	parts := strings.SplitN(value, ".", 2)
+	if _, e := state.Decode("1." + parts[1]); e == nil {
+		err(t, "a changed issue time should be invalid", e, ErrInvalidWizardState)
+	}
+}
+
+func TestWizardSession(t *testing.T) {
+	var saved map[string]interface{}
+	w := newTestWizard(SignedState{Secret: []byte("secret")}, &saved)
+	w.Session = "donald"
+	w.showStep()
+
+	n := nextRequest(w, &saved)
+	n.Session = "daisy"
+	if e := submitStep(n, url.Values{"Name": {"Daisy"}}); !errors.Is(e, ErrGeneralValidationErrors) || n.Current != 0 {
+		err(t, "the state of another session should be invalid", e, ErrInvalidWizardState)
+	}
+
+	n = nextRequest(w, &saved)
+	n.Session = "donald"
+	if e := submitStep(n, url.Values{"Name": {"Donald"}}); e != nil || n.Current != 1 {
+		err(t, "the state of the same session should be valid", e, nil)
+	}
+
+	if n.answers.Get(wizardSessionKey) != "" {
+		err(t, "the session should not be an answer", n.answers, "Name")
+	}
+}
+
+func TestWizardHooksRunAfterLastStep(t *testing.T) {
+	var saved map[string]interface{}
+	var before, after int
+	newWizard := func(state WizardState) *Wizard {
+		w := newTestWizard(state, &saved)
+		w.BeforeParsing = func(*FormHandler) { before++ }
+		w.AfterParsing = func(*FormHandler) { after++ }
+		return w
+	}
+
+	w := newWizard(NewMemoryState())
+	submitStep(w, url.Values{"Name": {"Donald"}})
+	n := newWizard(w.State)
+	n.Element = w.Element
+	submitStep(n, url.Values{"Email": {"donald@duck.de"}})
+
+	if before != 0 || after != 0 {
+		err(t, "the hooks should not run for intermediate steps", before, 0)
+	}
+
+	w = n
+	n = newWizard(w.State)
+	n.Element = w.Element
+	if e := submitStep(n, url.Values{}); e != nil || !n.Done {
+		t.Fatalf("unexpected error: %v", e)
+	}
+
+	if before != 1 || after != 1 {
+		err(t, "the hooks should run once after the last step", before, 1)
+	}
+}